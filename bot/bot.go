@@ -1,6 +1,7 @@
 package bot
 
 import (
+	"context"
 	"log/slog"
 
 	"forge.capytal.company/capytal/dislate/translator"
@@ -11,6 +12,8 @@ import (
 )
 
 type Bot struct {
+	ctx        context.Context
+	cancel     context.CancelFunc
 	token      string
 	db         gconf.DB
 	translator translator.Translator
@@ -29,7 +32,11 @@ func NewBot(
 		return &Bot{}, err
 	}
 
+	ctx, cancel := context.WithCancel(context.Background())
+
 	return &Bot{
+		ctx:        ctx,
+		cancel:     cancel,
 		token:      token,
 		db:         db,
 		translator: translator,
@@ -50,10 +57,16 @@ func (b *Bot) Start() error {
 	if err := b.registerCommands(); err != nil {
 		return err
 	}
+
+	go b.pruneMessages()
+
 	return nil
 }
 
+// Stops the bot, cancelling all translations still being made.
 func (b *Bot) Stop() error {
+	b.cancel()
+
 	if err := b.removeCommands(); err != nil {
 		return err
 	}
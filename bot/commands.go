@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log/slog"
 	"slices"
+	"strings"
 
 	"forge.capytal.company/capytal/dislate/bot/commands"
 
@@ -16,10 +17,15 @@ func (b *Bot) registerCommands() error {
 	cs := []commands.Command{
 		commands.NewMagageConfig(b.db),
 		commands.NewManageChannel(b.db),
+		commands.NewManageGlossary(b.db),
+		commands.NewDetect(b.ctx, b.translator),
+		commands.NewFlags(b.db),
+		commands.NewShowOriginal(b.db),
 	}
 
 	handlers := make(map[string]func(*dgo.Session, *dgo.InteractionCreate), len(cs))
 	componentsHandlers := make(map[string]func(*dgo.Session, *dgo.InteractionCreate))
+	autocompleteHandlers := make(map[string]func(*dgo.Session, *dgo.InteractionCreate))
 
 	for _, v := range cs {
 		var cmd *dgo.ApplicationCommand
@@ -53,7 +59,12 @@ func (b *Bot) registerCommands() error {
 			}
 		}
 
-		for _, c := range v.Components() {
+		components := v.Components()
+		for _, sb := range sb {
+			components = append(components, sb.Components()...)
+		}
+
+		for _, c := range components {
 			cj, err := c.Info().MarshalJSON()
 			if err != nil {
 				return errors.Join(fmt.Errorf("Failed to marshal command"), err)
@@ -81,6 +92,34 @@ func (b *Bot) registerCommands() error {
 			}
 		}
 
+		autocompleteHandlers[cmd.Name] = func(s *dgo.Session, ic *dgo.InteractionCreate) {
+			var ac commands.Autocompleter
+			var ok bool
+
+			opts := ic.Interaction.ApplicationCommandData().Options
+			isSub := slices.IndexFunc(
+				opts,
+				func(o *dgo.ApplicationCommandInteractionDataOption) bool {
+					return o.Type == dgo.ApplicationCommandOptionSubCommand
+				},
+			)
+			if isSub != -1 {
+				ac, ok = subCmds[opts[isSub].Name].(commands.Autocompleter)
+			} else {
+				ac, ok = v.(commands.Autocompleter)
+			}
+			if !ok {
+				return
+			}
+
+			if err := ac.Autocomplete(s, ic); err != nil {
+				b.logger.Error("Failed to handle autocomplete",
+					slog.String("name", cmd.Name),
+					slog.String("err", err.Error()),
+				)
+			}
+		}
+
 		handlers[cmd.Name] = func(s *dgo.Session, ic *dgo.InteractionCreate) {
 			b.logger.Debug("Handling command",
 				slog.String("id", ic.Interaction.ID),
@@ -150,8 +189,14 @@ func (b *Bot) registerCommands() error {
 			if h, ok := handlers[i.ApplicationCommandData().Name]; ok {
 				h(s, i)
 			}
+		case dgo.InteractionApplicationCommandAutocomplete:
+			if h, ok := autocompleteHandlers[i.ApplicationCommandData().Name]; ok {
+				h(s, i)
+			}
 		case dgo.InteractionMessageComponent:
-			if h, ok := componentsHandlers[i.MessageComponentData().CustomID]; ok {
+			// Custom IDs can carry state after the prefix the component was registered with
+			id, _, _ := strings.Cut(i.MessageComponentData().CustomID, ":")
+			if h, ok := componentsHandlers[id]; ok {
 				h(s, i)
 			}
 		}
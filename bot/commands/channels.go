@@ -3,6 +3,8 @@ package commands
 import (
 	"errors"
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
 
 	"forge.capytal.company/capytal/dislate/bot/gconf"
@@ -36,7 +38,14 @@ func (c ManageChannel) Subcommands() []Command {
 	return []Command{
 		channelsInfo(c),
 		channelsLink(c),
+		channelsUnlink(c),
+		channelsMerge(c),
+		channelsDissolve(c),
+		channelsGroups(c),
+		channelsList(c),
 		channelsSetLang(c),
+		channelsSetAutoDetect(c),
+		channelsSetStyle(c),
 	}
 }
 
@@ -203,13 +212,14 @@ func (c channelsLink) Handle(s *dgo.Session, ic *dgo.InteractionCreate) error {
 	}
 
 	if len(cb1) > 0 && len(cb2) > 0 {
-		return errors.New("both channels are already in a group")
+		if slices.ContainsFunc(cb1, func(gc guilddb.Channel) bool { return gc.ID == ch2.ID }) {
+			return errors.New("both channels are already linked")
+		}
+		return errors.New("both channels are already in a group, use /channel merge to join them")
 	} else if len(cb1) > 0 {
-		cb1 = append(cb1, ch2)
-		err = c.db.ChannelGroupUpdate(cb1)
+		err = c.db.ChannelGroupAdd(ch1, ch2)
 	} else if len(cb2) > 0 {
-		cb2 = append(cb2, ch1)
-		err = c.db.ChannelGroupUpdate(cb2)
+		err = c.db.ChannelGroupAdd(ch2, ch1)
 	} else {
 		err = c.db.ChannelGroupInsert(guilddb.ChannelGroup{ch1, ch2})
 	}
@@ -241,6 +251,439 @@ func (c channelsLink) Subcommands() []Command {
 	return []Command{}
 }
 
+type channelsUnlink struct {
+	db gconf.DB
+}
+
+func (c channelsUnlink) Info() *dgo.ApplicationCommand {
+	var permissions int64 = dgo.PermissionManageChannels
+
+	return &dgo.ApplicationCommand{
+		Name:                     "unlink",
+		Description:              "Remove a channel from its group",
+		DefaultMemberPermissions: &permissions,
+		Options: []*dgo.ApplicationCommandOption{{
+			Type:        dgo.ApplicationCommandOptionChannel,
+			Name:        "channel",
+			Description: "The channel to unlink",
+			ChannelTypes: []dgo.ChannelType{
+				dgo.ChannelTypeGuildText,
+				dgo.ChannelTypeGuildForum,
+				dgo.ChannelTypeGuildPublicThread,
+				dgo.ChannelTypeGuildPrivateThread,
+			},
+		}},
+	}
+}
+
+func (c channelsUnlink) Handle(s *dgo.Session, ic *dgo.InteractionCreate) error {
+	opts := getOptions(ic.ApplicationCommandData().Options)
+
+	var err error
+	var dch *dgo.Channel
+	if c, ok := opts["channel"]; ok {
+		dch = c.ChannelValue(s)
+	} else {
+		dch, err = s.Channel(ic.ChannelID)
+		if err != nil {
+			return err
+		}
+	}
+
+	ch, err := getChannel(c.db, dch.GuildID, dch.ID)
+	if err != nil {
+		return err
+	}
+
+	err = c.db.Tx(func(tx gdb.GuildDB[gconf.ConfigString]) error {
+		if err := tx.ChannelGroupRemove(ch); errors.Is(err, gdb.ErrNoAffect) {
+			return errors.New("channel is not linked to any other channel")
+		} else if err != nil {
+			return err
+		}
+		return unlinkMessages(tx, ch)
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.InteractionRespond(ic.Interaction, &dgo.InteractionResponse{
+		Type: dgo.InteractionResponseChannelMessageWithSource,
+		Data: &dgo.InteractionResponseData{
+			Content: fmt.Sprintf("Unlinked channel %s (%s)", dch.Name, dch.ID),
+			Flags:   dgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+func (c channelsUnlink) Components() []Component {
+	return []Component{}
+}
+
+func (c channelsUnlink) Subcommands() []Command {
+	return []Command{}
+}
+
+type channelsMerge struct {
+	db gconf.DB
+}
+
+func (c channelsMerge) Info() *dgo.ApplicationCommand {
+	var permissions int64 = dgo.PermissionManageChannels
+
+	return &dgo.ApplicationCommand{
+		Name:                     "merge",
+		Description:              "Move all channels of a group to the group of another channel",
+		DefaultMemberPermissions: &permissions,
+		Options: []*dgo.ApplicationCommandOption{{
+			Type:        dgo.ApplicationCommandOptionChannel,
+			Name:        "channel_one",
+			Description: "A channel of the group to move",
+			Required:    true,
+			ChannelTypes: []dgo.ChannelType{
+				dgo.ChannelTypeGuildText,
+				dgo.ChannelTypeGuildForum,
+				dgo.ChannelTypeGuildPublicThread,
+				dgo.ChannelTypeGuildPrivateThread,
+			},
+		}, {
+			Type:        dgo.ApplicationCommandOptionChannel,
+			Name:        "channel_two",
+			Description: "A channel of the group to move to",
+			ChannelTypes: []dgo.ChannelType{
+				dgo.ChannelTypeGuildText,
+				dgo.ChannelTypeGuildForum,
+				dgo.ChannelTypeGuildPublicThread,
+				dgo.ChannelTypeGuildPrivateThread,
+			},
+		}},
+	}
+}
+
+func (c channelsMerge) Handle(s *dgo.Session, ic *dgo.InteractionCreate) error {
+	opts := getOptions(ic.ApplicationCommandData().Options)
+
+	var err error
+	var dch1, dch2 *dgo.Channel
+	if c, ok := opts["channel_one"]; ok {
+		dch1 = c.ChannelValue(s)
+	} else {
+		return errors.New("channel_one is required")
+	}
+
+	if c, ok := opts["channel_two"]; ok {
+		dch2 = c.ChannelValue(s)
+	} else {
+		dch2, err = s.Channel(ic.ChannelID)
+		if err != nil {
+			return err
+		}
+	}
+
+	if dch1.Type != dch2.Type {
+		return errors.New("channel_one and channel_two must be the same channel types")
+	}
+
+	ch1, err := getChannel(c.db, dch1.GuildID, dch1.ID)
+	if err != nil {
+		return err
+	}
+	ch2, err := getChannel(c.db, dch2.GuildID, dch2.ID)
+	if err != nil {
+		return err
+	}
+
+	err = c.db.ChannelGroupMerge(ch2, ch1)
+	if errors.Is(err, gdb.ErrNotFound) {
+		return errors.New("both channels must be linked to a group, use /channel link instead")
+	} else if errors.Is(err, gdb.ErrNoAffect) {
+		return errors.New("both channels are already in the same group")
+	} else if err != nil {
+		return err
+	}
+
+	return s.InteractionRespond(ic.Interaction, &dgo.InteractionResponse{
+		Type: dgo.InteractionResponseChannelMessageWithSource,
+		Data: &dgo.InteractionResponseData{
+			Content: fmt.Sprintf(
+				"Merged the group of channel %s (%s) into the group of %s (%s)",
+				dch1.Name, dch1.ID, dch2.Name, dch2.ID,
+			),
+			Flags: dgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+func (c channelsMerge) Components() []Component {
+	return []Component{}
+}
+
+func (c channelsMerge) Subcommands() []Command {
+	return []Command{}
+}
+
+type channelsDissolve struct {
+	db gconf.DB
+}
+
+func (c channelsDissolve) Info() *dgo.ApplicationCommand {
+	var permissions int64 = dgo.PermissionManageChannels
+
+	return &dgo.ApplicationCommand{
+		Name:                     "dissolve",
+		Description:              "Unlink all channels of a group",
+		DefaultMemberPermissions: &permissions,
+		Options: []*dgo.ApplicationCommandOption{{
+			Type:        dgo.ApplicationCommandOptionChannel,
+			Name:        "channel",
+			Description: "A channel of the group to dissolve",
+			ChannelTypes: []dgo.ChannelType{
+				dgo.ChannelTypeGuildText,
+				dgo.ChannelTypeGuildForum,
+				dgo.ChannelTypeGuildPublicThread,
+				dgo.ChannelTypeGuildPrivateThread,
+			},
+		}},
+	}
+}
+
+func (c channelsDissolve) Handle(s *dgo.Session, ic *dgo.InteractionCreate) error {
+	opts := getOptions(ic.ApplicationCommandData().Options)
+
+	var err error
+	var dch *dgo.Channel
+	if c, ok := opts["channel"]; ok {
+		dch = c.ChannelValue(s)
+	} else {
+		dch, err = s.Channel(ic.ChannelID)
+		if err != nil {
+			return err
+		}
+	}
+
+	var g gdb.ChannelGroup
+	err = c.db.Tx(func(tx gdb.GuildDB[gconf.ConfigString]) error {
+		var err error
+		g, err = tx.ChannelGroup(dch.GuildID, dch.ID)
+		if errors.Is(err, gdb.ErrNotFound) {
+			return errors.New("channel is not linked to any other channel")
+		} else if err != nil {
+			return err
+		}
+
+		if err := tx.ChannelGroupDelete(g); err != nil {
+			return err
+		}
+		for _, ch := range g {
+			if err := unlinkMessages(tx, ch); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.InteractionRespond(ic.Interaction, &dgo.InteractionResponse{
+		Type: dgo.InteractionResponseChannelMessageWithSource,
+		Data: &dgo.InteractionResponseData{
+			Content: fmt.Sprintf("Unlinked channels %s", formatGroup(g)),
+			Flags:   dgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+func (c channelsDissolve) Components() []Component {
+	return []Component{}
+}
+
+func (c channelsDissolve) Subcommands() []Command {
+	return []Command{}
+}
+
+type channelsGroups struct {
+	db gconf.DB
+}
+
+func (c channelsGroups) Info() *dgo.ApplicationCommand {
+	var permissions int64 = dgo.PermissionManageChannels
+
+	return &dgo.ApplicationCommand{
+		Name:                     "groups",
+		Description:              "List the groups of linked channels",
+		DefaultMemberPermissions: &permissions,
+	}
+}
+
+// Groups are listed first by /channel list, so its pages and buttons are reused
+// instead of listing groups in a single embed without pages.
+func (c channelsGroups) Handle(s *dgo.Session, ic *dgo.InteractionCreate) error {
+	return channelsList(c).Handle(s, ic)
+}
+
+func (c channelsGroups) Components() []Component {
+	return []Component{}
+}
+
+func (c channelsGroups) Subcommands() []Command {
+	return []Command{}
+}
+
+type channelsList struct {
+	db gconf.DB
+}
+
+func (c channelsList) Info() *dgo.ApplicationCommand {
+	var permissions int64 = dgo.PermissionManageChannels
+
+	return &dgo.ApplicationCommand{
+		Name:                     "list",
+		Description:              "List all translated channels and their groups",
+		DefaultMemberPermissions: &permissions,
+	}
+}
+
+func (c channelsList) Handle(s *dgo.Session, ic *dgo.InteractionCreate) error {
+	embed, components, err := channelsListPage(c.db, ic.GuildID, 0)
+	if err != nil {
+		return err
+	}
+
+	return s.InteractionRespond(ic.Interaction, &dgo.InteractionResponse{
+		Type: dgo.InteractionResponseChannelMessageWithSource,
+		Data: &dgo.InteractionResponseData{
+			Embeds:     []*dgo.MessageEmbed{embed},
+			Components: components,
+			Flags:      dgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+func (c channelsList) Components() []Component {
+	return []Component{channelsListButton(c)}
+}
+
+func (c channelsList) Subcommands() []Command {
+	return []Command{}
+}
+
+const (
+	channelsListID       = "channel-list"
+	channelsListPageSize = 10
+)
+
+// Buttons to change the page of /channel list, their custom IDs have the page
+// they go to, like "channel-list:2".
+type channelsListButton struct {
+	db gconf.DB
+}
+
+func (c channelsListButton) Info() dgo.MessageComponent {
+	return dgo.Button{
+		Label:    "Next",
+		Style:    dgo.SecondaryButton,
+		CustomID: channelsListID,
+	}
+}
+
+func (c channelsListButton) Handle(s *dgo.Session, ic *dgo.InteractionCreate) error {
+	_, state, _ := strings.Cut(ic.MessageComponentData().CustomID, ":")
+	page, err := strconv.Atoi(state)
+	if err != nil {
+		return errors.Join(fmt.Errorf("Invalid page %q", state), err)
+	}
+
+	embed, components, err := channelsListPage(c.db, ic.GuildID, page)
+	if err != nil {
+		return err
+	}
+
+	return s.InteractionRespond(ic.Interaction, &dgo.InteractionResponse{
+		Type: dgo.InteractionResponseUpdateMessage,
+		Data: &dgo.InteractionResponseData{
+			Embeds:     []*dgo.MessageEmbed{embed},
+			Components: components,
+		},
+	})
+}
+
+// Builds a page of /channel list, with groups listed first and then the channels
+// which aren't linked. Pages out of range are clamped, since channels can change
+// between button presses.
+func channelsListPage(
+	db gconf.DB,
+	guildID string,
+	page int,
+) (*dgo.MessageEmbed, []dgo.MessageComponent, error) {
+	gs, err := db.ChannelGroups(guildID)
+	if err != nil && !errors.Is(err, gdb.ErrNotFound) {
+		return nil, nil, err
+	}
+	cs, err := db.Channels(guildID)
+	if err != nil && !errors.Is(err, gdb.ErrNotFound) {
+		return nil, nil, err
+	}
+
+	var lines []string
+	grouped := make(map[string]bool)
+	for i, g := range gs {
+		ls := make([]string, len(g))
+		for j, ch := range g {
+			ls[j] = fmt.Sprintf("<#%s> (%s)", ch.ID, ch.Language.Name())
+			grouped[ch.ID] = true
+		}
+		lines = append(lines, fmt.Sprintf("**Group %d:** %s", i+1, strings.Join(ls, ", ")))
+	}
+	for _, ch := range cs {
+		if !grouped[ch.ID] {
+			lines = append(lines, fmt.Sprintf("<#%s> (%s)", ch.ID, ch.Language.Name()))
+		}
+	}
+
+	embed := &dgo.MessageEmbed{Title: "Translated Channels"}
+	if len(lines) == 0 {
+		embed.Description = "No channels are translated, use /channel set-lang to add them"
+		return embed, nil, nil
+	}
+
+	pages := (len(lines) + channelsListPageSize - 1) / channelsListPageSize
+	page = max(0, min(page, pages-1))
+
+	start := page * channelsListPageSize
+	end := min(start+channelsListPageSize, len(lines))
+	embed.Description = strings.Join(lines[start:end], "\n")
+	embed.Footer = &dgo.MessageEmbedFooter{
+		Text: fmt.Sprintf(
+			"Page %d of %d, %d groups and %d channels",
+			page+1, pages, len(gs), len(cs),
+		),
+	}
+
+	if pages == 1 {
+		return embed, nil, nil
+	}
+
+	// Both buttons need different custom IDs, which they have since there is
+	// more than one page
+	prev := dgo.Button{
+		Label:    "Previous",
+		Style:    dgo.SecondaryButton,
+		Disabled: page == 0,
+		CustomID: fmt.Sprintf("%s:%d", channelsListID, max(page-1, 0)),
+	}
+	next := dgo.Button{
+		Label:    "Next",
+		Style:    dgo.SecondaryButton,
+		Disabled: page == pages-1,
+		CustomID: fmt.Sprintf("%s:%d", channelsListID, min(page+1, pages-1)),
+	}
+
+	return embed, []dgo.MessageComponent{
+		dgo.ActionsRow{Components: []dgo.MessageComponent{prev, next}},
+	}, nil
+}
+
 type channelsSetLang struct {
 	db gconf.DB
 }
@@ -250,17 +693,14 @@ func (c channelsSetLang) Info() *dgo.ApplicationCommand {
 
 	return &dgo.ApplicationCommand{
 		Name:                     "set-lang",
-		Description:              "Link two channels together",
+		Description:              "Change the language of a channel",
 		DefaultMemberPermissions: &permissions,
 		Options: []*dgo.ApplicationCommandOption{{
-			Type:        dgo.ApplicationCommandOptionString,
-			Required:    true,
-			Name:        "language",
-			Description: "The new language",
-			Choices: []*dgo.ApplicationCommandOptionChoice{
-				{Name: "English (EN)", Value: translator.EN},
-				{Name: "Portuguese (PT)", Value: translator.PT},
-			},
+			Type:         dgo.ApplicationCommandOptionString,
+			Required:     true,
+			Name:         "language",
+			Description:  "The new language",
+			Autocomplete: true,
 		}, {
 			Type:        dgo.ApplicationCommandOptionChannel,
 			Name:        "channel",
@@ -283,11 +723,9 @@ func (c channelsSetLang) Handle(s *dgo.Session, ic *dgo.InteractionCreate) error
 	var l translator.Language
 
 	if c, ok := opts["language"]; ok {
-		switch c.StringValue() {
-		case string(translator.PT):
-			l = translator.PT
-		default:
-			l = translator.EN
+		l, err = translator.ParseLanguage(c.StringValue())
+		if err != nil {
+			return err
 		}
 	} else {
 		return errors.New("language is a required option")
@@ -319,7 +757,7 @@ func (c channelsSetLang) Handle(s *dgo.Session, ic *dgo.InteractionCreate) error
 		Data: &dgo.InteractionResponseData{
 			Content: fmt.Sprintf(
 				"Changed language of channel %s (%s) to %s",
-				dch.Name, dch.ID, l,
+				dch.Name, dch.ID, l.Name(),
 			),
 			Flags: dgo.MessageFlagsEphemeral,
 		},
@@ -331,6 +769,10 @@ func (c channelsSetLang) Handle(s *dgo.Session, ic *dgo.InteractionCreate) error
 	return nil
 }
 
+func (c channelsSetLang) Autocomplete(s *dgo.Session, ic *dgo.InteractionCreate) error {
+	return languageAutocomplete(s, ic)
+}
+
 func (c channelsSetLang) Components() []Component {
 	return []Component{}
 }
@@ -339,10 +781,219 @@ func (c channelsSetLang) Subcommands() []Command {
 	return []Command{}
 }
 
+type channelsSetAutoDetect struct {
+	db gconf.DB
+}
+
+func (c channelsSetAutoDetect) Info() *dgo.ApplicationCommand {
+	var permissions int64 = dgo.PermissionManageChannels
+
+	return &dgo.ApplicationCommand{
+		Name:                     "auto-detect",
+		Description:              "Detect the language of each message sent in a channel",
+		DefaultMemberPermissions: &permissions,
+		Options: []*dgo.ApplicationCommandOption{{
+			Type:        dgo.ApplicationCommandOptionBoolean,
+			Required:    true,
+			Name:        "enabled",
+			Description: "Detect the language of messages, falling back to the channel's language",
+		}, {
+			Type:        dgo.ApplicationCommandOptionChannel,
+			Name:        "channel",
+			Description: "The channel to change",
+			ChannelTypes: []dgo.ChannelType{
+				dgo.ChannelTypeGuildText,
+				dgo.ChannelTypeGuildForum,
+				dgo.ChannelTypeGuildPublicThread,
+				dgo.ChannelTypeGuildPrivateThread,
+			},
+		}},
+	}
+}
+
+func (c channelsSetAutoDetect) Handle(s *dgo.Session, ic *dgo.InteractionCreate) error {
+	opts := getOptions(ic.ApplicationCommandData().Options)
+
+	var err error
+	var dch *dgo.Channel
+
+	opt, ok := opts["enabled"]
+	if !ok {
+		return errors.New("enabled is a required option")
+	}
+
+	if c, ok := opts["channel"]; ok {
+		dch = c.ChannelValue(s)
+	} else {
+		dch, err = s.Channel(ic.ChannelID)
+		if err != nil {
+			return err
+		}
+	}
+
+	ch, err := getChannel(c.db, dch.GuildID, dch.ID)
+	if err != nil {
+		return err
+	}
+
+	ch.AutoDetect = opt.BoolValue()
+
+	err = c.db.ChannelUpdate(ch)
+	if err != nil {
+		return err
+	}
+
+	state := "Disabled"
+	if ch.AutoDetect {
+		state = "Enabled"
+	}
+
+	return s.InteractionRespond(ic.Interaction, &dgo.InteractionResponse{
+		Type: dgo.InteractionResponseChannelMessageWithSource,
+		Data: &dgo.InteractionResponseData{
+			Content: fmt.Sprintf(
+				"%s language detection in channel %s (%s)",
+				state, dch.Name, dch.ID,
+			),
+			Flags: dgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+func (c channelsSetAutoDetect) Components() []Component {
+	return []Component{}
+}
+
+func (c channelsSetAutoDetect) Subcommands() []Command {
+	return []Command{}
+}
+
+type channelsSetStyle struct {
+	db gconf.DB
+}
+
+func (c channelsSetStyle) Info() *dgo.ApplicationCommand {
+	var permissions int64 = dgo.PermissionManageChannels
+
+	return &dgo.ApplicationCommand{
+		Name:                     "set-style",
+		Description:              "Change how messages are translated to a channel",
+		DefaultMemberPermissions: &permissions,
+		Options: []*dgo.ApplicationCommandOption{{
+			Type:        dgo.ApplicationCommandOptionString,
+			Name:        "formality",
+			Description: "The formality of translations, if supported by the provider",
+			Choices: []*dgo.ApplicationCommandOptionChoice{
+				{Name: "Default", Value: translator.FormalityDefault.String()},
+				{Name: "Formal", Value: translator.FormalityFormal.String()},
+				{Name: "Informal", Value: translator.FormalityInformal.String()},
+			},
+		}, {
+			Type:        dgo.ApplicationCommandOptionBoolean,
+			Name:        "preserve-profanity",
+			Description: "Keep profanity as is, if the provider would censor it",
+		}, {
+			Type:        dgo.ApplicationCommandOptionChannel,
+			Name:        "channel",
+			Description: "The channel to change",
+			ChannelTypes: []dgo.ChannelType{
+				dgo.ChannelTypeGuildText,
+				dgo.ChannelTypeGuildForum,
+				dgo.ChannelTypeGuildPublicThread,
+				dgo.ChannelTypeGuildPrivateThread,
+			},
+		}},
+	}
+}
+
+func (c channelsSetStyle) Handle(s *dgo.Session, ic *dgo.InteractionCreate) error {
+	opts := getOptions(ic.ApplicationCommandData().Options)
+
+	var err error
+	var dch *dgo.Channel
+	if c, ok := opts["channel"]; ok {
+		dch = c.ChannelValue(s)
+	} else {
+		dch, err = s.Channel(ic.ChannelID)
+		if err != nil {
+			return err
+		}
+	}
+
+	ch, err := getChannel(c.db, dch.GuildID, dch.ID)
+	if err != nil {
+		return err
+	}
+
+	// Options which aren't provided are kept as they are
+	if opt, ok := opts["formality"]; ok {
+		ch.Options.Formality, err = translator.ParseFormality(opt.StringValue())
+		if err != nil {
+			return err
+		}
+	}
+	if opt, ok := opts["preserve-profanity"]; ok {
+		ch.Options.PreserveProfanity = opt.BoolValue()
+	}
+
+	err = c.db.ChannelUpdate(ch)
+	if err != nil {
+		return err
+	}
+
+	return s.InteractionRespond(ic.Interaction, &dgo.InteractionResponse{
+		Type: dgo.InteractionResponseChannelMessageWithSource,
+		Data: &dgo.InteractionResponseData{
+			Content: fmt.Sprintf(
+				"Changed translation style of channel %s (%s) to %s",
+				dch.Name, dch.ID, formatOptions(ch.Options),
+			),
+			Flags: dgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+func (c channelsSetStyle) Components() []Component {
+	return []Component{}
+}
+
+func (c channelsSetStyle) Subcommands() []Command {
+	return []Command{}
+}
+
+func formatOptions(o translator.Options) string {
+	p := "not preserved"
+	if o.PreserveProfanity {
+		p = "preserved"
+	}
+	return fmt.Sprintf("%s formality, profanity %s", o.Formality, p)
+}
+
+// Deletes the messages of a channel which was unlinked and their translations,
+// since they would map to channels which it isn't linked to anymore.
+func unlinkMessages(db gconf.DB, ch gdb.Channel) error {
+	if err := db.MessageDeleteFromChannel(ch); err != nil && !errors.Is(err, gdb.ErrNoAffect) {
+		return err
+	}
+	return nil
+}
+
+func formatGroup(g gdb.ChannelGroup) string {
+	cs := make([]string, len(g))
+	for i, ch := range g {
+		cs[i] = "<#" + ch.ID + ">"
+	}
+	return strings.Join(cs, ", ")
+}
+
 func getChannel(db gconf.DB, guildID, channelID string) (gdb.Channel, error) {
 	ch, err := db.Channel(guildID, channelID)
 	if errors.Is(err, gdb.ErrNotFound) {
-		if err := db.ChannelInsert(gdb.NewChannel(guildID, channelID, translator.EN)); err != nil {
+		conf := gconf.GetEffectiveConfig(guildID, db)
+		ch = gdb.NewChannel(guildID, channelID, conf.DefaultLanguage)
+		ch.AutoDetect = conf.DefaultAutoDetect
+
+		if err := db.ChannelInsert(ch); err != nil {
 			return gdb.Channel{}, err
 		}
 		ch, err = db.Channel(guildID, channelID)
@@ -362,17 +1013,42 @@ func getChannelInfo(db gconf.DB, ch gdb.Channel) (*dgo.MessageEmbed, error) {
 		return nil, err
 	}
 
-	g := make([]string, len(group))
-	for i, gi := range group {
-		g[i] = "<#" + gi.ID + ">"
-	}
-
 	return &dgo.MessageEmbed{
 		Title: "Channel Information",
 		Fields: []*dgo.MessageEmbedField{
 			{Name: "ID", Value: ch.ID, Inline: true},
-			{Name: "Language", Value: string(ch.Language), Inline: true},
-			{Name: "Linked Channels", Value: strings.Join(g, ", "), Inline: true},
+			{Name: "Language", Value: ch.Language.Name(), Inline: true},
+			{Name: "Auto-detect", Value: strconv.FormatBool(ch.AutoDetect), Inline: true},
+			{Name: "Style", Value: formatOptions(ch.Options), Inline: true},
+			{Name: "Linked Channels", Value: formatGroup(group), Inline: true},
 		},
 	}, nil
 }
+
+func languageAutocomplete(s *dgo.Session, ic *dgo.InteractionCreate) error {
+	opt, ok := getFocusedOption(ic.ApplicationCommandData().Options)
+	if !ok || opt.Name != "language" {
+		return nil
+	}
+
+	return s.InteractionRespond(ic.Interaction, &dgo.InteractionResponse{
+		Type: dgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &dgo.InteractionResponseData{
+			Choices: languageChoices(opt.StringValue()),
+		},
+	})
+}
+
+func languageChoices(query string) []*dgo.ApplicationCommandOptionChoice {
+	ls := translator.SearchLanguages(query)
+	if len(ls) > 25 {
+		ls = ls[:25]
+	}
+
+	cs := make([]*dgo.ApplicationCommandOptionChoice, len(ls))
+	for i, l := range ls {
+		cs[i] = &dgo.ApplicationCommandOptionChoice{Name: l.String(), Value: string(l.Code)}
+	}
+
+	return cs
+}
@@ -11,6 +11,15 @@ type Command interface {
 	Components() []Component
 }
 
+// Commands with options that set Autocomplete can implement this interface to
+// respond with the suggested choices.
+type Autocompleter interface {
+	Autocomplete(s *dgo.Session, i *dgo.InteractionCreate) error
+}
+
+// Message components which are sent by a Command. The custom ID of Info is used
+// to route interactions to Handle, components sent in messages can have state
+// after it separated by ":", like "custom-id:state".
 type Component interface {
 	Info() dgo.MessageComponent
 	Handle(s *dgo.Session, i *dgo.InteractionCreate) error
@@ -31,3 +40,17 @@ func getOptions(
 
 	return m
 }
+
+func getFocusedOption(
+	opts []*dgo.ApplicationCommandInteractionDataOption,
+) (*dgo.ApplicationCommandInteractionDataOption, bool) {
+	for _, opt := range opts {
+		if opt.Type == dgo.ApplicationCommandOptionSubCommand {
+			return getFocusedOption(opt.Options)
+		} else if opt.Focused {
+			return opt, true
+		}
+	}
+
+	return nil, false
+}
@@ -1,11 +1,15 @@
 package commands
 
 import (
+	"bytes"
+	"encoding/json"
 	e "errors"
 	"fmt"
 	"log/slog"
+	"strings"
 
 	"forge.capytal.company/capytal/dislate/bot/gconf"
+	"forge.capytal.company/capytal/dislate/guilddb"
 
 	dgo "github.com/bwmarrin/discordgo"
 )
@@ -40,6 +44,11 @@ func (c ManageConfig) Subcommands() []Command {
 	return []Command{
 		loggerConfigChannel(c),
 		loggerConfigLevel(c),
+		flagConfigEmoji(c),
+		retentionConfig(c),
+		exportConfig(c),
+		showConfig(c),
+		setConfig(c),
 	}
 }
 
@@ -185,3 +194,335 @@ func (c loggerConfigLevel) Components() []Component {
 func (c loggerConfigLevel) Subcommands() []Command {
 	return []Command{}
 }
+
+type flagConfigEmoji struct {
+	db gconf.DB
+}
+
+func (c flagConfigEmoji) Info() *dgo.ApplicationCommand {
+	var permissions int64 = dgo.PermissionAdministrator
+	return &dgo.ApplicationCommand{
+		Name:                     "flag-emoji",
+		Description:              "Change the reaction used to flag bad translations",
+		DefaultMemberPermissions: &permissions,
+		Options: []*dgo.ApplicationCommandOption{{
+			Type:        dgo.ApplicationCommandOptionString,
+			Required:    true,
+			Name:        "emoji",
+			Description: "The emoji users react with to flag a translation",
+		}},
+	}
+}
+
+func (c flagConfigEmoji) Handle(s *dgo.Session, ic *dgo.InteractionCreate) error {
+	opts := getOptions(ic.ApplicationCommandData().Options)
+
+	opt, ok := opts["emoji"]
+	if !ok || strings.TrimSpace(opt.StringValue()) == "" {
+		return e.New("Parameter emoji is required")
+	}
+	emoji := strings.TrimSpace(opt.StringValue())
+
+	guild, err := c.db.Guild(ic.GuildID)
+	if err != nil {
+		return err
+	}
+
+	conf := guild.Config
+	conf.FlagEmoji = &emoji
+	guild.Config = conf
+
+	err = c.db.GuildUpdate(guild)
+	if err != nil {
+		return err
+	}
+
+	err = s.InteractionRespond(ic.Interaction, &dgo.InteractionResponse{
+		Type: dgo.InteractionResponseChannelMessageWithSource,
+		Data: &dgo.InteractionResponseData{
+			Content: fmt.Sprintf("Translations are now flagged with %s", emoji),
+			Flags:   dgo.MessageFlagsEphemeral,
+		},
+	})
+
+	return err
+}
+
+func (c flagConfigEmoji) Components() []Component {
+	return []Component{}
+}
+
+func (c flagConfigEmoji) Subcommands() []Command {
+	return []Command{}
+}
+
+type retentionConfig struct {
+	db gconf.DB
+}
+
+func (c retentionConfig) Info() *dgo.ApplicationCommand {
+	var permissions int64 = dgo.PermissionAdministrator
+	var min float64 = 0
+	return &dgo.ApplicationCommand{
+		Name:                     "retention",
+		Description:              "Change how long translated messages are remembered",
+		DefaultMemberPermissions: &permissions,
+		Options: []*dgo.ApplicationCommandOption{{
+			Type:        dgo.ApplicationCommandOptionInteger,
+			Required:    true,
+			Name:        "days",
+			Description: "Days messages are kept before being forgotten, 0 to keep them forever",
+			MinValue:    &min,
+		}},
+	}
+}
+
+func (c retentionConfig) Handle(s *dgo.Session, ic *dgo.InteractionCreate) error {
+	opts := getOptions(ic.ApplicationCommandData().Options)
+
+	opt, ok := opts["days"]
+	if !ok {
+		return e.New("Parameter days is required")
+	}
+	days := int(opt.IntValue())
+	if days < 0 {
+		return e.New("Parameter days must not be negative")
+	}
+
+	guild, err := c.db.Guild(ic.GuildID)
+	if err != nil {
+		return err
+	}
+
+	conf := guild.Config
+	conf.MessageRetentionDays = &days
+	guild.Config = conf
+
+	err = c.db.GuildUpdate(guild)
+	if err != nil {
+		return err
+	}
+
+	content := fmt.Sprintf("Messages are now forgotten after %d days", days)
+	if days == 0 {
+		content = "Messages are now kept forever"
+	}
+
+	err = s.InteractionRespond(ic.Interaction, &dgo.InteractionResponse{
+		Type: dgo.InteractionResponseChannelMessageWithSource,
+		Data: &dgo.InteractionResponseData{
+			Content: content,
+			Flags:   dgo.MessageFlagsEphemeral,
+		},
+	})
+
+	return err
+}
+
+func (c retentionConfig) Components() []Component {
+	return []Component{}
+}
+
+func (c retentionConfig) Subcommands() []Command {
+	return []Command{}
+}
+
+type exportConfig struct {
+	db gconf.DB
+}
+
+func (c exportConfig) Info() *dgo.ApplicationCommand {
+	var permissions int64 = dgo.PermissionAdministrator
+	return &dgo.ApplicationCommand{
+		Name:                     "export",
+		Description:              "Export the guild's configuration, channels and groups as a file",
+		DefaultMemberPermissions: &permissions,
+		Options: []*dgo.ApplicationCommandOption{{
+			Type:        dgo.ApplicationCommandOptionBoolean,
+			Required:    false,
+			Name:        "messages",
+			Description: "Also export which messages are translations of each other",
+		}},
+	}
+}
+
+func (c exportConfig) Handle(s *dgo.Session, ic *dgo.InteractionCreate) error {
+	opts := getOptions(ic.ApplicationCommandData().Options)
+
+	var messages bool
+	if opt, ok := opts["messages"]; ok {
+		messages = opt.BoolValue()
+	}
+
+	export, err := guilddb.ExportGuild[gconf.ConfigString](c.db, ic.GuildID, messages)
+	if err != nil {
+		return e.Join(e.New("Failed to export guild"), err)
+	}
+
+	b, err := json.MarshalIndent(export, "", "\t")
+	if err != nil {
+		return e.Join(e.New("Failed to encode guild export"), err)
+	}
+
+	err = s.InteractionRespond(ic.Interaction, &dgo.InteractionResponse{
+		Type: dgo.InteractionResponseChannelMessageWithSource,
+		Data: &dgo.InteractionResponseData{
+			Content: "Guild exported, it can be imported with `dislate import <file>`",
+			Files: []*dgo.File{{
+				Name:        fmt.Sprintf("dislate-%s.json", ic.GuildID),
+				ContentType: "application/json",
+				Reader:      bytes.NewReader(b),
+			}},
+			Flags: dgo.MessageFlagsEphemeral,
+		},
+	})
+
+	return err
+}
+
+func (c exportConfig) Components() []Component {
+	return []Component{}
+}
+
+func (c exportConfig) Subcommands() []Command {
+	return []Command{}
+}
+
+type showConfig struct {
+	db gconf.DB
+}
+
+func (c showConfig) Info() *dgo.ApplicationCommand {
+	var permissions int64 = dgo.PermissionAdministrator
+	return &dgo.ApplicationCommand{
+		Name:                     "show",
+		Description:              "Show the guild's configuration, including default values",
+		DefaultMemberPermissions: &permissions,
+	}
+}
+
+func (c showConfig) Handle(s *dgo.Session, ic *dgo.InteractionCreate) error {
+	guild, err := c.db.Guild(ic.GuildID)
+	if err != nil {
+		return err
+	}
+
+	opts := guild.Config.Options()
+	fields := make([]*dgo.MessageEmbedField, len(opts))
+	for i, o := range opts {
+		v := o.Value
+		if o.Default {
+			v += " *(default)*"
+		}
+		fields[i] = &dgo.MessageEmbedField{Name: o.Name, Value: v, Inline: true}
+	}
+
+	err = s.InteractionRespond(ic.Interaction, &dgo.InteractionResponse{
+		Type: dgo.InteractionResponseChannelMessageWithSource,
+		Data: &dgo.InteractionResponseData{
+			Embeds: []*dgo.MessageEmbed{{
+				Title:  "Guild Configuration",
+				Fields: fields,
+				Footer: &dgo.MessageEmbedFooter{
+					Text: fmt.Sprintf("Config version %d", guild.Config.Version),
+				},
+			}},
+			Flags: dgo.MessageFlagsEphemeral,
+		},
+	})
+
+	return err
+}
+
+func (c showConfig) Components() []Component {
+	return []Component{}
+}
+
+func (c showConfig) Subcommands() []Command {
+	return []Command{}
+}
+
+type setConfig struct {
+	db gconf.DB
+}
+
+func (c setConfig) Info() *dgo.ApplicationCommand {
+	var permissions int64 = dgo.PermissionAdministrator
+
+	choices := make([]*dgo.ApplicationCommandOptionChoice, len(gconf.ConfigOptionNames))
+	for i, n := range gconf.ConfigOptionNames {
+		choices[i] = &dgo.ApplicationCommandOptionChoice{Name: n, Value: n}
+	}
+
+	return &dgo.ApplicationCommand{
+		Name:                     "set",
+		Description:              "Change an option of the guild's configuration",
+		DefaultMemberPermissions: &permissions,
+		Options: []*dgo.ApplicationCommandOption{{
+			Type:        dgo.ApplicationCommandOptionString,
+			Required:    true,
+			Name:        "option",
+			Description: "The option to change",
+			Choices:     choices,
+		}, {
+			Type:        dgo.ApplicationCommandOptionString,
+			Required:    true,
+			Name:        "value",
+			Description: "The new value of the option, \"default\" to use the default value",
+		}},
+	}
+}
+
+func (c setConfig) Handle(s *dgo.Session, ic *dgo.InteractionCreate) error {
+	opts := getOptions(ic.ApplicationCommandData().Options)
+
+	name, ok := opts["option"]
+	if !ok {
+		return e.New("Parameter option is required")
+	}
+	value, ok := opts["value"]
+	if !ok {
+		return e.New("Parameter value is required")
+	}
+
+	guild, err := c.db.Guild(ic.GuildID)
+	if err != nil {
+		return err
+	}
+
+	conf := guild.Config
+	if err := conf.Set(name.StringValue(), value.StringValue()); err != nil {
+		return err
+	}
+	guild.Config = conf
+
+	err = c.db.GuildUpdate(guild)
+	if err != nil {
+		return err
+	}
+
+	var v string
+	for _, o := range conf.Options() {
+		if o.Name == name.StringValue() {
+			v = o.Value
+		}
+	}
+
+	err = s.InteractionRespond(ic.Interaction, &dgo.InteractionResponse{
+		Type: dgo.InteractionResponseChannelMessageWithSource,
+		Data: &dgo.InteractionResponseData{
+			Content: fmt.Sprintf("Option %s changed to %s", name.StringValue(), v),
+			Flags:   dgo.MessageFlagsEphemeral,
+		},
+	})
+
+	return err
+}
+
+func (c setConfig) Components() []Component {
+	return []Component{}
+}
+
+func (c setConfig) Subcommands() []Command {
+	return []Command{}
+}
@@ -0,0 +1,86 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"forge.capytal.company/capytal/dislate/translator"
+
+	dgo "github.com/bwmarrin/discordgo"
+)
+
+// Interactions need to be responded in 3 seconds.
+const detectTimeout = 2 * time.Second
+
+type Detect struct {
+	ctx        context.Context
+	translator translator.Translator
+}
+
+func NewDetect(ctx context.Context, t translator.Translator) Detect {
+	return Detect{ctx, t}
+}
+
+func (c Detect) Info() *dgo.ApplicationCommand {
+	var permissions int64 = dgo.PermissionManageMessages
+
+	return &dgo.ApplicationCommand{
+		Name:                     "detect",
+		Description:              "Shows the detected languages of a text",
+		DefaultMemberPermissions: &permissions,
+		Options: []*dgo.ApplicationCommandOption{{
+			Type:        dgo.ApplicationCommandOptionString,
+			Required:    true,
+			Name:        "text",
+			Description: "The text to detect the language of",
+		}},
+	}
+}
+
+func (c Detect) Handle(s *dgo.Session, ic *dgo.InteractionCreate) error {
+	opts := getOptions(ic.ApplicationCommandData().Options)
+
+	opt, ok := opts["text"]
+	if !ok {
+		return errors.New("text is a required option")
+	}
+
+	ctx, cancel := context.WithTimeout(c.ctx, detectTimeout)
+	defer cancel()
+
+	d, err := c.translator.DetectAll(ctx, opt.StringValue())
+	if err != nil {
+		return err
+	}
+
+	var desc strings.Builder
+	for i, l := range d {
+		info, _ := l.Language.Info()
+		desc.WriteString(fmt.Sprintf("%d. %s — %.1f%%\n", i+1, info, l.Confidence*100))
+	}
+	if len(d) == 0 {
+		desc.WriteString("*No language detected*")
+	}
+
+	return s.InteractionRespond(ic.Interaction, &dgo.InteractionResponse{
+		Type: dgo.InteractionResponseChannelMessageWithSource,
+		Data: &dgo.InteractionResponseData{
+			Embeds: []*dgo.MessageEmbed{{
+				Title:       "Detected Languages",
+				Description: desc.String(),
+			}},
+			Flags: dgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+func (c Detect) Components() []Component {
+	return []Component{}
+}
+
+func (c Detect) Subcommands() []Command {
+	return []Command{}
+}
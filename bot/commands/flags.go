@@ -0,0 +1,159 @@
+package commands
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"forge.capytal.company/capytal/dislate/bot/gconf"
+	"forge.capytal.company/capytal/dislate/translator"
+
+	gdb "forge.capytal.company/capytal/dislate/guilddb"
+
+	dgo "github.com/bwmarrin/discordgo"
+)
+
+// How many flagged translations are fetched from the database.
+const flaggedTranslationsLimit = 200
+
+// How many translations are listed for each provider and language pair.
+const flaggedTranslationsPerGroup = 3
+
+type Flags struct {
+	db gconf.DB
+}
+
+func NewFlags(db gconf.DB) Flags {
+	return Flags{db}
+}
+
+func (c Flags) Info() *dgo.ApplicationCommand {
+	var permissions int64 = dgo.PermissionManageMessages
+
+	return &dgo.ApplicationCommand{
+		Name:                     "flags",
+		Description:              "List the most flagged translations by provider and language pair",
+		DefaultMemberPermissions: &permissions,
+		Options: []*dgo.ApplicationCommandOption{{
+			Type:        dgo.ApplicationCommandOptionString,
+			Name:        "provider",
+			Description: "Only list translations of this provider",
+		}, {
+			Type:         dgo.ApplicationCommandOptionString,
+			Name:         "from",
+			Description:  "Only list translations from this language",
+			Autocomplete: true,
+		}, {
+			Type:         dgo.ApplicationCommandOptionString,
+			Name:         "to",
+			Description:  "Only list translations to this language",
+			Autocomplete: true,
+		}},
+	}
+}
+
+func (c Flags) Handle(s *dgo.Session, ic *dgo.InteractionCreate) error {
+	opts := getOptions(ic.ApplicationCommandData().Options)
+
+	var from, to translator.Language
+	for name, l := range map[string]*translator.Language{"from": &from, "to": &to} {
+		if opt, ok := opts[name]; ok && opt.StringValue() != "" {
+			var err error
+			if *l, err = translator.ParseLanguage(opt.StringValue()); err != nil {
+				return err
+			}
+		}
+	}
+	var provider string
+	if opt, ok := opts["provider"]; ok {
+		provider = strings.TrimSpace(opt.StringValue())
+	}
+
+	filter := gdb.FlagFilter{Provider: provider, From: from, To: to}
+	fs, err := c.db.FlaggedTranslations(ic.GuildID, filter, flaggedTranslationsLimit)
+	if err != nil && !errors.Is(err, gdb.ErrNotFound) {
+		return err
+	}
+
+	type group struct {
+		key          string
+		flags        int
+		translations []gdb.FlaggedTranslation
+	}
+	var gs []*group
+	for _, f := range fs {
+		p := f.Provider
+		if p == "" {
+			p = "unknown provider"
+		}
+		key := fmt.Sprintf("%s: %s → %s", p, f.From.Name(), f.To.Name())
+
+		i := slices.IndexFunc(gs, func(g *group) bool { return g.key == key })
+		if i == -1 {
+			gs = append(gs, &group{key: key})
+			i = len(gs) - 1
+		}
+		gs[i].flags += f.Flags
+		gs[i].translations = append(gs[i].translations, f)
+	}
+	slices.SortStableFunc(gs, func(a, b *group) int { return cmp.Compare(b.flags, a.flags) })
+
+	// Embeds are limited to 25 fields
+	fields := make([]*dgo.MessageEmbedField, 0, min(len(gs), 25))
+	for _, g := range gs[:min(len(gs), 25)] {
+		var v strings.Builder
+		for _, f := range g.translations[:min(len(g.translations), flaggedTranslationsPerGroup)] {
+			v.WriteString(fmt.Sprintf(
+				"- %s ([original](%s)) flagged %d times\n",
+				messageURL(f.GuildID, f.ChannelID, f.MessageID),
+				messageURL(f.GuildID, f.OriginChannelID, f.OriginID),
+				f.Flags,
+			))
+		}
+		fields = append(fields, &dgo.MessageEmbedField{
+			Name:  fmt.Sprintf("%s (%d flags)", g.key, g.flags),
+			Value: v.String(),
+		})
+	}
+
+	desc := ""
+	if len(fields) == 0 {
+		desc = "*No flagged translations*"
+	}
+
+	return s.InteractionRespond(ic.Interaction, &dgo.InteractionResponse{
+		Type: dgo.InteractionResponseChannelMessageWithSource,
+		Data: &dgo.InteractionResponseData{
+			Embeds: []*dgo.MessageEmbed{{
+				Title:       "Flagged Translations",
+				Description: desc,
+				Fields:      fields,
+			}},
+			Flags: dgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+func (c Flags) Autocomplete(s *dgo.Session, ic *dgo.InteractionCreate) error {
+	opt, ok := getFocusedOption(ic.ApplicationCommandData().Options)
+	if !ok || (opt.Name != "from" && opt.Name != "to") {
+		return nil
+	}
+
+	return s.InteractionRespond(ic.Interaction, &dgo.InteractionResponse{
+		Type: dgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &dgo.InteractionResponseData{
+			Choices: languageChoices(opt.StringValue()),
+		},
+	})
+}
+
+func (c Flags) Components() []Component {
+	return []Component{}
+}
+
+func (c Flags) Subcommands() []Command {
+	return []Command{}
+}
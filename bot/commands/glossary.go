@@ -0,0 +1,436 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"forge.capytal.company/capytal/dislate/bot/gconf"
+	"forge.capytal.company/capytal/dislate/translator"
+
+	gdb "forge.capytal.company/capytal/dislate/guilddb"
+
+	dgo "github.com/bwmarrin/discordgo"
+)
+
+type ManageGlossary struct {
+	db gconf.DB
+}
+
+func NewManageGlossary(db gconf.DB) ManageGlossary {
+	return ManageGlossary{db}
+}
+
+func (c ManageGlossary) Info() *dgo.ApplicationCommand {
+	var permissions int64 = dgo.PermissionManageServer
+
+	return &dgo.ApplicationCommand{
+		Name:                     "glossary",
+		Description:              "Manages the terms that shouldn't be translated",
+		DefaultMemberPermissions: &permissions,
+	}
+}
+
+func (c ManageGlossary) Subcommands() []Command {
+	return []Command{
+		glossaryAdd(c),
+		glossaryRemove(c),
+		glossaryList(c),
+		glossaryImport(c),
+	}
+}
+
+func (c ManageGlossary) Handle(s *dgo.Session, i *dgo.InteractionCreate) error {
+	return nil
+}
+
+func (c ManageGlossary) Components() []Component {
+	return []Component{}
+}
+
+type glossaryAdd struct {
+	db gconf.DB
+}
+
+func (c glossaryAdd) Info() *dgo.ApplicationCommand {
+	var permissions int64 = dgo.PermissionManageServer
+
+	return &dgo.ApplicationCommand{
+		Name:                     "add",
+		Description:              "Add or change a term of the glossary",
+		DefaultMemberPermissions: &permissions,
+		Options: []*dgo.ApplicationCommandOption{{
+			Type:        dgo.ApplicationCommandOptionString,
+			Required:    true,
+			Name:        "term",
+			Description: "The term to not be translated",
+		}, {
+			Type:        dgo.ApplicationCommandOptionString,
+			Name:        "replacement",
+			Description: "The text to use in place of the term, keeps the term as is if empty",
+		}, {
+			Type:         dgo.ApplicationCommandOptionString,
+			Name:         "language",
+			Description:  "The language of the replacement, applies to all languages if empty",
+			Autocomplete: true,
+		}},
+	}
+}
+
+func (c glossaryAdd) Handle(s *dgo.Session, ic *dgo.InteractionCreate) error {
+	opts := getOptions(ic.ApplicationCommandData().Options)
+
+	t, err := getGlossaryTerm(ic.GuildID, opts)
+	if err != nil {
+		return err
+	}
+	if r, ok := opts["replacement"]; ok && r.StringValue() != "" {
+		v := r.StringValue()
+		t.Replacement = &v
+	}
+
+	if err := setGlossaryTerm(c.db, t); err != nil {
+		return err
+	}
+
+	return s.InteractionRespond(ic.Interaction, &dgo.InteractionResponse{
+		Type: dgo.InteractionResponseChannelMessageWithSource,
+		Data: &dgo.InteractionResponseData{
+			Content: fmt.Sprintf("Added %s to the glossary", formatGlossaryTerm(t)),
+			Flags:   dgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+func (c glossaryAdd) Autocomplete(s *dgo.Session, ic *dgo.InteractionCreate) error {
+	return languageAutocomplete(s, ic)
+}
+
+func (c glossaryAdd) Components() []Component {
+	return []Component{}
+}
+
+func (c glossaryAdd) Subcommands() []Command {
+	return []Command{}
+}
+
+type glossaryRemove struct {
+	db gconf.DB
+}
+
+func (c glossaryRemove) Info() *dgo.ApplicationCommand {
+	var permissions int64 = dgo.PermissionManageServer
+
+	return &dgo.ApplicationCommand{
+		Name:                     "remove",
+		Description:              "Remove a term from the glossary",
+		DefaultMemberPermissions: &permissions,
+		Options: []*dgo.ApplicationCommandOption{{
+			Type:        dgo.ApplicationCommandOptionString,
+			Required:    true,
+			Name:        "term",
+			Description: "The term to remove",
+		}, {
+			Type:         dgo.ApplicationCommandOptionString,
+			Name:         "language",
+			Description:  "Only remove the replacement of this language",
+			Autocomplete: true,
+		}},
+	}
+}
+
+func (c glossaryRemove) Handle(s *dgo.Session, ic *dgo.InteractionCreate) error {
+	opts := getOptions(ic.ApplicationCommandData().Options)
+
+	t, err := getGlossaryTerm(ic.GuildID, opts)
+	if err != nil {
+		return err
+	}
+
+	var ts []gdb.GlossaryTerm
+	if _, ok := opts["language"]; ok {
+		ts = []gdb.GlossaryTerm{t}
+	} else {
+		all, err := c.db.GlossaryTerms(ic.GuildID)
+		if err != nil && !errors.Is(err, gdb.ErrNotFound) {
+			return err
+		}
+		for _, gt := range all {
+			if strings.EqualFold(gt.Term, t.Term) {
+				ts = append(ts, gt)
+			}
+		}
+	}
+
+	n := 0
+	for _, t := range ts {
+		err := c.db.GlossaryTermDelete(t)
+		if err != nil && !errors.Is(err, gdb.ErrNoAffect) {
+			return err
+		} else if err == nil {
+			n++
+		}
+	}
+	if n == 0 {
+		return fmt.Errorf("Term %q is not in the glossary", t.Term)
+	}
+
+	return s.InteractionRespond(ic.Interaction, &dgo.InteractionResponse{
+		Type: dgo.InteractionResponseChannelMessageWithSource,
+		Data: &dgo.InteractionResponseData{
+			Content: fmt.Sprintf("Removed %d entries of %q from the glossary", n, t.Term),
+			Flags:   dgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+func (c glossaryRemove) Autocomplete(s *dgo.Session, ic *dgo.InteractionCreate) error {
+	return languageAutocomplete(s, ic)
+}
+
+func (c glossaryRemove) Components() []Component {
+	return []Component{}
+}
+
+func (c glossaryRemove) Subcommands() []Command {
+	return []Command{}
+}
+
+type glossaryList struct {
+	db gconf.DB
+}
+
+func (c glossaryList) Info() *dgo.ApplicationCommand {
+	var permissions int64 = dgo.PermissionManageServer
+
+	return &dgo.ApplicationCommand{
+		Name:                     "list",
+		Description:              "List all terms of the glossary",
+		DefaultMemberPermissions: &permissions,
+	}
+}
+
+func (c glossaryList) Handle(s *dgo.Session, ic *dgo.InteractionCreate) error {
+	ts, err := c.db.GlossaryTerms(ic.GuildID)
+	if err != nil && !errors.Is(err, gdb.ErrNotFound) {
+		return err
+	}
+
+	var desc strings.Builder
+	for i, t := range ts {
+		l := "- " + formatGlossaryTerm(t) + "\n"
+		// Embed descriptions are limited to 4096 characters
+		if desc.Len()+len(l) > 4000 {
+			desc.WriteString(fmt.Sprintf("*And %d more terms*", len(ts)-i))
+			break
+		}
+		desc.WriteString(l)
+	}
+	if len(ts) == 0 {
+		desc.WriteString("*No terms in the glossary*")
+	}
+
+	return s.InteractionRespond(ic.Interaction, &dgo.InteractionResponse{
+		Type: dgo.InteractionResponseChannelMessageWithSource,
+		Data: &dgo.InteractionResponseData{
+			Embeds: []*dgo.MessageEmbed{{
+				Title:       "Glossary",
+				Description: desc.String(),
+			}},
+			Flags: dgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+func (c glossaryList) Components() []Component {
+	return []Component{}
+}
+
+func (c glossaryList) Subcommands() []Command {
+	return []Command{}
+}
+
+type glossaryImport struct {
+	db gconf.DB
+}
+
+func (c glossaryImport) Info() *dgo.ApplicationCommand {
+	var permissions int64 = dgo.PermissionManageServer
+
+	return &dgo.ApplicationCommand{
+		Name:                     "import",
+		Description:              "Import terms to the glossary from a CSV file",
+		DefaultMemberPermissions: &permissions,
+		Options: []*dgo.ApplicationCommandOption{{
+			Type:        dgo.ApplicationCommandOptionAttachment,
+			Required:    true,
+			Name:        "file",
+			Description: "CSV file with the columns term, language and replacement",
+		}},
+	}
+}
+
+const (
+	// Maximum size of imported CSV files, in bytes.
+	glossaryImportMaxSize = 1 << 20
+	glossaryImportTimeout = 30 * time.Second
+)
+
+var glossaryImportClient = &http.Client{Timeout: glossaryImportTimeout}
+
+func (c glossaryImport) Handle(s *dgo.Session, ic *dgo.InteractionCreate) error {
+	opts := getOptions(ic.ApplicationCommandData().Options)
+
+	opt, ok := opts["file"]
+	if !ok {
+		return errors.New("file is a required option")
+	}
+	id, _ := opt.Value.(string)
+	att, ok := ic.ApplicationCommandData().Resolved.Attachments[id]
+	if !ok {
+		return errors.New("Failed to get attached file")
+	}
+
+	if att.Size > glossaryImportMaxSize {
+		return fmt.Errorf("File is bigger than the limit of %d bytes", glossaryImportMaxSize)
+	}
+
+	r, err := glossaryImportClient.Get(att.URL)
+	if err != nil {
+		return errors.Join(errors.New("Failed to download attached file"), err)
+	}
+	defer r.Body.Close()
+
+	if r.StatusCode != http.StatusOK {
+		return fmt.Errorf("Failed to download attached file, responded with status %d", r.StatusCode)
+	}
+
+	// One more byte is read, so files bigger than the limit aren't silently cut
+	f, err := io.ReadAll(io.LimitReader(r.Body, glossaryImportMaxSize+1))
+	if err != nil {
+		return errors.Join(errors.New("Failed to download attached file"), err)
+	} else if len(f) > glossaryImportMaxSize {
+		return fmt.Errorf("File is bigger than the limit of %d bytes", glossaryImportMaxSize)
+	}
+
+	ts, err := parseGlossaryCSV(ic.GuildID, bytes.NewReader(f))
+	if err != nil {
+		return err
+	}
+
+	// Terms are imported in a single transaction, so a failure doesn't leave
+	// half of the file in the glossary
+	err = c.db.Tx(func(tx gdb.GuildDB[gconf.ConfigString]) error {
+		for _, t := range ts {
+			if err := setGlossaryTerm(tx, t); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.InteractionRespond(ic.Interaction, &dgo.InteractionResponse{
+		Type: dgo.InteractionResponseChannelMessageWithSource,
+		Data: &dgo.InteractionResponseData{
+			Content: fmt.Sprintf("Imported %d terms to the glossary", len(ts)),
+			Flags:   dgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+func (c glossaryImport) Components() []Component {
+	return []Component{}
+}
+
+func (c glossaryImport) Subcommands() []Command {
+	return []Command{}
+}
+
+func getGlossaryTerm(
+	guildID string,
+	opts map[string]*dgo.ApplicationCommandInteractionDataOption,
+) (gdb.GlossaryTerm, error) {
+	opt, ok := opts["term"]
+	if !ok || strings.TrimSpace(opt.StringValue()) == "" {
+		return gdb.GlossaryTerm{}, errors.New("term is a required option")
+	}
+
+	var l translator.Language
+	if opt, ok := opts["language"]; ok && opt.StringValue() != "" {
+		var err error
+		l, err = translator.ParseLanguage(opt.StringValue())
+		if err != nil {
+			return gdb.GlossaryTerm{}, err
+		}
+	}
+
+	return gdb.NewGlossaryTerm(guildID, strings.TrimSpace(opt.StringValue()), l, nil), nil
+}
+
+func setGlossaryTerm(db gconf.DB, t gdb.GlossaryTerm) error {
+	err := db.GlossaryTermInsert(t)
+	if errors.Is(err, gdb.ErrNoAffect) {
+		err = db.GlossaryTermUpdate(t)
+	}
+	return err
+}
+
+// Parses a CSV file with the columns term, language and replacement. The header
+// row is optional, and empty language or replacement columns have the same meaning
+// as in /glossary add.
+func parseGlossaryCSV(guildID string, f io.Reader) ([]gdb.GlossaryTerm, error) {
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, errors.Join(errors.New("Failed to parse CSV file"), err)
+	}
+	if len(rows) > 0 && len(rows[0]) > 0 && strings.EqualFold(rows[0][0], "term") {
+		rows = rows[1:]
+	}
+
+	ts := make([]gdb.GlossaryTerm, 0, len(rows))
+	for i, row := range rows {
+		if len(row) == 0 || strings.TrimSpace(row[0]) == "" {
+			continue
+		}
+
+		t := gdb.NewGlossaryTerm(guildID, strings.TrimSpace(row[0]), "", nil)
+		if len(row) > 1 && strings.TrimSpace(row[1]) != "" {
+			t.Language, err = translator.ParseLanguage(row[1])
+			if err != nil {
+				return nil, errors.Join(fmt.Errorf("Invalid language in row %d", i+1), err)
+			}
+		}
+		if len(row) > 2 && row[2] != "" {
+			v := row[2]
+			t.Replacement = &v
+		}
+
+		ts = append(ts, t)
+	}
+
+	return ts, nil
+}
+
+func formatGlossaryTerm(t gdb.GlossaryTerm) string {
+	l := "all languages"
+	if t.Language != "" {
+		l = t.Language.Name()
+	}
+
+	if t.Replacement == nil {
+		return fmt.Sprintf("**%s** is kept as is in %s", t.Term, l)
+	}
+	return fmt.Sprintf("**%s** is replaced by **%s** in %s", t.Term, *t.Replacement, l)
+}
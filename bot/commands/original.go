@@ -0,0 +1,84 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+
+	"forge.capytal.company/capytal/dislate/bot/gconf"
+
+	gdb "forge.capytal.company/capytal/dislate/guilddb"
+
+	dgo "github.com/bwmarrin/discordgo"
+)
+
+// ShowOriginal is a message command which shows the original text of a
+// translated message to the user only.
+type ShowOriginal struct {
+	db gconf.DB
+}
+
+func NewShowOriginal(db gconf.DB) ShowOriginal {
+	return ShowOriginal{db}
+}
+
+func (c ShowOriginal) Info() *dgo.ApplicationCommand {
+	return &dgo.ApplicationCommand{
+		Type: dgo.MessageApplicationCommand,
+		Name: "Show original",
+	}
+}
+
+func (c ShowOriginal) Handle(s *dgo.Session, ic *dgo.InteractionCreate) error {
+	data := ic.ApplicationCommandData()
+
+	m, err := c.db.Message(ic.GuildID, ic.ChannelID, data.TargetID)
+	if errors.Is(err, gdb.ErrNotFound) || (err == nil && m.OriginID == nil) {
+		return errors.New("This message is not a translation")
+	} else if err != nil {
+		return err
+	}
+
+	origin, err := c.db.Message(m.GuildID, *m.OriginChannelID, *m.OriginID)
+	if err != nil {
+		return errors.Join(errors.New("Failed to get original message from database"), err)
+	}
+
+	dm, err := s.ChannelMessage(origin.ChannelID, origin.ID)
+	if err != nil {
+		return errors.Join(errors.New("Failed to get original message"), err)
+	}
+
+	footer := fmt.Sprintf("Translated from %s to %s", origin.Language.Name(), m.Language.Name())
+	if m.Provider != nil {
+		footer += " by " + *m.Provider
+	}
+	footer += fmt.Sprintf(
+		". React with %s to the translation if it is wrong.",
+		gconf.GetFlagEmoji(ic.GuildID, c.db),
+	)
+
+	return s.InteractionRespond(ic.Interaction, &dgo.InteractionResponse{
+		Type: dgo.InteractionResponseChannelMessageWithSource,
+		Data: &dgo.InteractionResponseData{
+			Embeds: []*dgo.MessageEmbed{{
+				Title:       "Original message",
+				URL:         messageURL(origin.GuildID, origin.ChannelID, origin.ID),
+				Description: dm.Content,
+				Footer:      &dgo.MessageEmbedFooter{Text: footer},
+			}},
+			Flags: dgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+func (c ShowOriginal) Components() []Component {
+	return []Component{}
+}
+
+func (c ShowOriginal) Subcommands() []Command {
+	return []Command{}
+}
+
+func messageURL(guildID, channelID, ID string) string {
+	return fmt.Sprintf("https://discord.com/channels/%s/%s/%s", guildID, channelID, ID)
+}
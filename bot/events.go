@@ -20,11 +20,13 @@ func w[E any](h events.EventHandler[E]) interface{} {
 func (b *Bot) registerEventHandlers() {
 	ehs := []any{
 		w(events.NewGuildCreate(b.logger, b.db)),
-		w(events.NewMessageCreate(b.db, b.translator)),
-		w(events.NewMessageUpdate(b.db, b.translator)),
+		w(events.NewMessageCreate(b.ctx, b.db, b.translator)),
+		w(events.NewMessageUpdate(b.ctx, b.db, b.translator)),
 		w(events.NewMessageDelete(b.db)),
+		w(events.NewMessageReactionAdd(b.db)),
+		w(events.NewMessageReactionRemove(b.db)),
 		w(events.NewReady(b.logger, b.db)),
-		w(events.NewThreadCreate(b.db, b.translator)),
+		w(events.NewThreadCreate(b.ctx, b.db, b.translator)),
 	}
 	for _, h := range ehs {
 		b.session.AddHandler(h)
@@ -0,0 +1,26 @@
+package errors
+
+import (
+	"log/slog"
+
+	dgo "github.com/bwmarrin/discordgo"
+)
+
+type ReactionErr[E any] struct {
+	*defaultEventErr[E]
+}
+
+// Errors of reactions are only logged, so users aren't notified in the channel
+// about reactions they may not even have made.
+func NewReactionErr[E any](r *dgo.MessageReaction, log *slog.Logger) ReactionErr[E] {
+	return ReactionErr[E]{&defaultEventErr[E]{
+		data: map[string]any{
+			"MessageID": r.MessageID,
+			"ChannelID": r.ChannelID,
+			"GuildID":   r.GuildID,
+			"UserID":    r.UserID,
+			"Emoji":     r.Emoji.APIName(),
+		},
+		logger: log,
+	}}
+}
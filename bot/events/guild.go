@@ -22,7 +22,7 @@ func NewGuildCreate(log *slog.Logger, db gconf.DB) GuildCreate {
 }
 
 func (h GuildCreate) Serve(s *dgo.Session, ev *dgo.GuildCreate) errors.EventErr {
-	err := h.db.GuildInsert(gdb.Guild[gconf.ConfigString]{ID: ev.Guild.ID})
+	err := h.db.GuildInsert(gdb.NewGuild(ev.Guild.ID, gconf.NewConfig()))
 
 	everr := errors.NewGuildErr[*dgo.GuildCreate](ev.Guild, h.log)
 
@@ -50,7 +50,7 @@ func (h Ready) Serve(s *dgo.Session, ev *dgo.Ready) errors.EventErr {
 	everr := errors.NewReadyErr(ev, h.log)
 
 	for _, g := range ev.Guilds {
-		err := h.db.GuildInsert(gdb.Guild[gconf.ConfigString]{ID: g.ID})
+		err := h.db.GuildInsert(gdb.NewGuild(g.ID, gconf.NewConfig()))
 
 		if err != nil && !e.Is(err, gdb.ErrNoAffect) {
 			return everr.Join(err)
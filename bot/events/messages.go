@@ -1,10 +1,14 @@
 package events
 
 import (
+	"context"
 	e "errors"
 	"log/slog"
 	"slices"
 	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"forge.capytal.company/capytal/dislate/bot/events/errors"
 	"forge.capytal.company/capytal/dislate/bot/gconf"
@@ -15,12 +19,15 @@ import (
 )
 
 type MessageCreate struct {
+	ctx        context.Context
 	db         gconf.DB
 	translator translator.Translator
 }
 
-func NewMessageCreate(db gconf.DB, t translator.Translator) MessageCreate {
-	return MessageCreate{db, t}
+func NewMessageCreate(ctx context.Context, db gconf.DB, t translator.Translator) MessageCreate {
+	// New messages are translated before anything else, so conversations
+	// aren't delayed by edits.
+	return MessageCreate{ctx, db, translator.WithPriority(t, translator.PriorityHigh)}
 }
 
 func (h MessageCreate) Serve(
@@ -66,13 +73,46 @@ func (h MessageCreate) sendMessage(
 		return everr.Join(e.New("Failed to get channel group from database"), err)
 	}
 
-	_, err = getMessage(h.db, msg, ch.Language)
+	conf := gconf.GetEffectiveConfig(msg.GuildID, h.db)
+	if utf8.RuneCountInString(msg.Content) > conf.MaxMessageLength {
+		log.Debug("Message is longer than the guild's limit, ignoring.",
+			slog.String("guild", msg.GuildID),
+			slog.String("channel", msg.ChannelID),
+			slog.String("message", msg.ID),
+		)
+		return nil
+	}
+
+	lang := ch.Language
+	if ch.AutoDetect {
+		lang = detectLanguage(h.ctx, log, h.translator, msg.Content, ch.Language)
+	}
+
+	_, err = getMessage(h.db, msg, lang)
 	if err != nil {
 		return everr.Join(e.New("Failed to get/add message to database"), err)
 	}
 
+	gt, err := glossaryTranslator(h.db, msg.GuildID, h.translator)
+	if err != nil {
+		return everr.Join(e.New("Failed to get glossary from database"), err)
+	}
+
+	langs := make(map[translator.Options][]translator.Language)
+	for _, c := range gc {
+		if c.ID == ch.ID || c.Language == lang {
+			continue
+		}
+		if !slices.Contains(langs[c.Options], c.Language) {
+			langs[c.Options] = append(langs[c.Options], c.Language)
+		}
+	}
+
+	// Languages which failed to be translated only fail their channels
+	ts, terr := translateStyled(h.ctx, log, gt, lang, langs, []string{msg.Content})
+
 	var wg sync.WaitGroup
-	errs := make(chan errors.EventErr)
+	errs := make(chan errors.EventErr, len(gc))
 
 	for _, c := range gc {
 		if c.ID == ch.ID && c.GuildID == ch.GuildID {
@@ -103,23 +143,30 @@ func (h MessageCreate) sendMessage(
 				return
 			}
 
-			t, err := h.translator.Translate(ch.Language, c.Language, msg.Content)
-			if err != nil {
-				errs <- everr.Join(e.New("Error while trying to translate message"), err)
-				return
+			// Messages already in the channel's language don't need to be translated
+			t := msg.Content
+			var provider string
+			if c.Language != lang {
+				r, ok := ts[c.Options][c.Language]
+				if !ok {
+					errs <- everr.Join(e.New("Error while trying to translate message"), terr)
+					return
+				}
+				t = r.Texts[0]
+				provider = r.Provider
 			}
 
 			var tdm *dgo.Message
 			if dch.IsThread() {
 				tdm, err = s.WebhookThreadExecute(uw.ID, uw.Token, true, dch.ID, &dgo.WebhookParams{
 					AvatarURL: msg.Author.AvatarURL(""),
-					Username:  msg.Author.GlobalName,
+					Username:  conf.Username(msg.Author.GlobalName, c.Language),
 					Content:   t,
 				})
 			} else {
 				tdm, err = s.WebhookExecute(uw.ID, uw.Token, true, &dgo.WebhookParams{
 					AvatarURL: msg.Author.AvatarURL(""),
-					Username:  msg.Author.GlobalName,
+					Username:  conf.Username(msg.Author.GlobalName, c.Language),
 					Content:   t,
 				})
 			}
@@ -133,7 +180,7 @@ func (h MessageCreate) sendMessage(
 				tdm.GuildID = msg.GuildID
 			}
 
-			_, err = getTranslatedMessage(h.db, tdm, msg, c.Language)
+			_, err = getTranslatedMessage(h.db, tdm, msg, c.Language, provider)
 			if err != nil {
 				everr.AddData("WebhookID", uw.ID)
 				everr.AddData("TranslatedMessageID", uw.ID)
@@ -144,24 +191,30 @@ func (h MessageCreate) sendMessage(
 
 	}
 
+	// Each goroutine sends at most one error, so none of them block on a full
+	// channel and it can be closed after all are done.
 	wg.Wait()
+	close(errs)
+
+	everrs := make([]error, 0, len(errs))
 	for err := range errs {
-		everr.Join(err)
+		everrs = append(everrs, err)
 	}
-	if len(errs) > 0 {
-		return everr
+	if len(everrs) > 0 {
+		return everr.Join(everrs...)
 	}
 
 	return nil
 }
 
 type MessageUpdate struct {
+	ctx        context.Context
 	db         gconf.DB
 	translator translator.Translator
 }
 
-func NewMessageUpdate(db gconf.DB, t translator.Translator) MessageUpdate {
-	return MessageUpdate{db, t}
+func NewMessageUpdate(ctx context.Context, db gconf.DB, t translator.Translator) MessageUpdate {
+	return MessageUpdate{ctx, db, translator.WithPriority(t, translator.PriorityLow)}
 }
 
 func (h MessageUpdate) Serve(s *dgo.Session, ev *dgo.MessageUpdate) errors.EventErr {
@@ -172,6 +225,17 @@ func (h MessageUpdate) Serve(s *dgo.Session, ev *dgo.MessageUpdate) errors.Event
 	log := gconf.GetLogger(ev.Message.GuildID, s, h.db)
 	everr := errors.NewMessageErr[*dgo.MessageUpdate](s, ev.Message, log)
 
+	conf := gconf.GetEffectiveConfig(ev.Message.GuildID, h.db)
+	if !conf.TranslateEdits {
+		return nil
+	} else if utf8.RuneCountInString(ev.Message.Content) > conf.MaxMessageLength {
+		log.Debug("Edited message is longer than the guild's limit, ignoring.",
+			slog.String("guild", ev.Message.GuildID),
+			slog.String("channel", ev.Message.ChannelID),
+		)
+		return nil
+	}
+
 	msg, err := h.db.Message(ev.Message.GuildID, ev.Message.ChannelID, ev.Message.ID)
 	if e.Is(err, guilddb.ErrNotFound) {
 		log.Debug("Message is not in database, ignoring.",
@@ -194,8 +258,30 @@ func (h MessageUpdate) Serve(s *dgo.Session, ev *dgo.MessageUpdate) errors.Event
 		return everr.Join(e.New("Failed to get translated messages from database"), err)
 	}
 
+	gt, err := glossaryTranslator(h.db, msg.GuildID, h.translator)
+	if err != nil {
+		return everr.Join(e.New("Failed to get glossary from database"), err)
+	}
+
+	// Translated messages are edited with the current options of their channels
+	opts := make(map[string]translator.Options, len(tmsgs))
+	langs := make(map[translator.Options][]translator.Language)
+	for _, m := range tmsgs {
+		c, err := h.db.Channel(m.GuildID, m.ChannelID)
+		if err != nil && !e.Is(err, guilddb.ErrNotFound) {
+			return everr.Join(e.New("Failed to get translated channel from database"), err)
+		}
+		opts[m.ID] = c.Options
+
+		if m.Language != msg.Language && !slices.Contains(langs[c.Options], m.Language) {
+			langs[c.Options] = append(langs[c.Options], m.Language)
+		}
+	}
+
+	ts, terr := translateStyled(h.ctx, log, gt, msg.Language, langs, []string{ev.Message.Content})
+
 	var wg sync.WaitGroup
-	errs := make(chan errors.EventErr)
+	errs := make(chan errors.EventErr, len(tmsgs))
 
 	for _, m := range tmsgs {
 		if m.ID == msg.ID && m.GuildID == msg.GuildID {
@@ -225,10 +311,18 @@ func (h MessageUpdate) Serve(s *dgo.Session, ev *dgo.MessageUpdate) errors.Event
 				return
 			}
 
-			t, err := h.translator.Translate(msg.Language, m.Language, ev.Message.Content)
-			if err != nil {
-				errs <- everr.Join(e.New("Error while trying to translate message"), err)
-				return
+			t := ev.Message.Content
+			var provider *string
+			if m.Language != msg.Language {
+				r, ok := ts[opts[m.ID]][m.Language]
+				if !ok {
+					errs <- everr.Join(e.New("Error while trying to translate message"), terr)
+					return
+				}
+				t = r.Texts[0]
+				if p := r.Provider; p != "" {
+					provider = &p
+				}
 			}
 
 			_, err = s.WebhookMessageEdit(uw.ID, uw.Token, m.ID, &dgo.WebhookEdit{
@@ -239,16 +333,27 @@ func (h MessageUpdate) Serve(s *dgo.Session, ev *dgo.MessageUpdate) errors.Event
 				errs <- everr.Join(e.New("Error while trying to execute user webhook"), err)
 				return
 			}
+
+			// Flags are reported by provider, so it must be the one of the latest
+			// translation.
+			m.Provider = provider
+			if err := h.db.MessageUpdate(m); err != nil && !e.Is(err, guilddb.ErrNoAffect) {
+				errs <- everr.Join(e.New("Failed to update translated message in database"), err)
+				return
+			}
 		}(m, errs)
 
 	}
 
 	wg.Wait()
+	close(errs)
+
+	everrs := make([]error, 0, len(errs))
 	for err := range errs {
-		everr.Join(err)
+		everrs = append(everrs, err)
 	}
-	if len(errs) > 0 {
-		return everr
+	if len(everrs) > 0 {
+		return everr.Join(everrs...)
 	}
 
 	return nil
@@ -331,44 +436,219 @@ func (h MessageDelete) Serve(s *dgo.Session, ev *dgo.MessageDelete) errors.Event
 		)
 	}
 
-	var wg sync.WaitGroup
-	errs := make(chan errors.EventErr)
+	// Threads have the same ID as their starter messages, so the threads of the
+	// deleted messages are also removed.
+	err = h.db.Tx(func(tx guilddb.GuildDB[gconf.ConfigString]) error {
+		for _, m := range append(tmsgs, msg) {
+			th := guilddb.NewChannel(m.GuildID, m.ID, translator.EN)
 
-	for _, m := range append(tmsgs, msg) {
-		go func(m guilddb.Message, errs chan<- errors.EventErr) {
-			everr := errors.NewMessageErr[*dgo.MessageUpdate](s, ev.Message, log)
-			everr.AddData("TranslatedMessageID", m.ID)
-			everr.AddData("TranslatedChannelID", m.ChannelID)
+			err := tx.MessageDeleteFromChannel(th)
+			if err != nil && !e.Is(err, guilddb.ErrNoAffect) {
+				return e.Join(e.New("Failed to delete messages of message thread"), err)
+			}
 
-			err := h.db.MessageDeleteFromChannel(guilddb.NewChannel(m.GuildID, m.ID, translator.EN))
+			err = tx.ChannelGroupRemove(th)
 			if err != nil && !e.Is(err, guilddb.ErrNoAffect) {
-				errs <- everr.Join(e.New("Failed to delete message from channel"), err)
-				return
+				return e.Join(e.New("Failed to remove message thread from its group"), err)
 			}
 
-			err = h.db.ChannelDelete(guilddb.NewChannel(m.GuildID, m.ID, translator.EN))
+			err = tx.ChannelDelete(th)
 			if err != nil && !e.Is(err, guilddb.ErrNoAffect) {
-				errs <- everr.Join(e.New("Failed to delete message thread from channel"), err)
-				return
+				return e.Join(e.New("Failed to delete message thread from database"), err)
 			}
-		}(m, errs)
+		}
+
+		err := tx.MessageDelete(guilddb.NewMessage(msg.GuildID, msg.ChannelID, msg.ID, translator.EN))
+		if err != nil {
+			return e.Join(e.New("Failed to delete message from database"), err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return everr.Join(err)
 	}
 
-	wg.Wait()
+	return nil
+}
 
-	everrs := make([]error, 0, len(errs))
-	for err := range errs {
-		everrs = append(everrs, err)
+// How long a single translation or detection may take, including the time
+// waiting in the translation queue.
+const translationTimeout = 30 * time.Second
+
+// Minimum number of letters in a message for its detected language to be trusted.
+const minDetectionLetters = 12
+
+// Minimum confidence of a detection for it to be trusted.
+const minDetectionConfidence = 0.5
+
+func detectLanguage(
+	ctx context.Context,
+	log *slog.Logger,
+	t translator.Translator,
+	text string,
+	fallback translator.Language,
+) translator.Language {
+	text = translator.StripMarkdown(text)
+
+	letters := 0
+	for _, r := range text {
+		if unicode.IsLetter(r) {
+			letters++
+		}
 	}
-	if len(errs) > 0 {
-		return everr.Join(everrs...)
+	if letters < minDetectionLetters {
+		return fallback
 	}
 
-	if err := h.db.MessageDelete(guilddb.NewMessage(msg.GuildID, msg.ChannelID, msg.ID, translator.EN)); err != nil {
-		return everr.Join(e.New("Failed to delete message from database"), err)
+	ctx, cancel := context.WithTimeout(ctx, translationTimeout)
+	defer cancel()
+
+	d, err := t.DetectAll(ctx, text)
+	if err != nil {
+		log.Debug("Failed to detect language, using channel's language",
+			slog.String("language", string(fallback)),
+			slog.String("err", err.Error()),
+		)
+		return fallback
 	}
 
-	return nil
+	l, ok := d.Best()
+	if !ok || !l.Language.IsValid() || l.Confidence < minDetectionConfidence {
+		log.Debug("Language detection not confident enough, using channel's language",
+			slog.String("detected", string(l.Language)),
+			slog.Float64("confidence", l.Confidence),
+			slog.String("language", string(fallback)),
+		)
+		return fallback
+	}
+
+	return l.Language
+}
+
+func glossaryTranslator(
+	db gconf.DB,
+	guildID string,
+	t translator.Translator,
+) (translator.Translator, error) {
+	ts, err := db.GlossaryTerms(guildID)
+	if e.Is(err, guilddb.ErrNotFound) {
+		return t, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	es := make([]translator.GlossaryEntry, len(ts))
+	for i, gt := range ts {
+		es[i] = translator.GlossaryEntry{
+			Term:        gt.Term,
+			Language:    gt.Language,
+			Replacement: gt.Replacement,
+		}
+	}
+
+	return translator.NewGlossary(t, es), nil
+}
+
+func translate(
+	ctx context.Context,
+	log *slog.Logger,
+	t translator.Translator,
+	from, to translator.Language,
+	text string,
+	opts translator.Options,
+) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, translationTimeout)
+	defer cancel()
+
+	pt, ok := t.(translator.ProviderTranslator)
+	if !ok {
+		return t.Translate(ctx, from, to, text, opts)
+	}
+
+	r, p, err := pt.TranslateWithProvider(ctx, from, to, text, opts)
+	if err != nil {
+		return r, err
+	}
+	log.Debug("Translated text",
+		slog.String("provider", p),
+		slog.String("from", string(from)),
+		slog.String("to", string(to)),
+	)
+
+	return r, nil
+}
+
+// Translates all texts to all languages in a single batch, logging which
+// provider translated each language.
+func translateBatch(
+	ctx context.Context,
+	log *slog.Logger,
+	t translator.Translator,
+	from translator.Language,
+	to []translator.Language,
+	texts []string,
+	opts translator.Options,
+) (map[translator.Language]translator.BatchResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, translationTimeout)
+	defer cancel()
+
+	res, err := translator.TranslateBatch(ctx, t, from, to, texts, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	for l, r := range res {
+		log.Debug("Translated texts",
+			slog.String("provider", r.Provider),
+			slog.String("from", string(from)),
+			slog.String("to", string(l)),
+			slog.Int("texts", len(texts)),
+		)
+	}
+
+	return res, nil
+}
+
+// Translates all texts with each of the options, to the languages of the
+// options. If a batch fails its languages are translated one by one, so a
+// single failing language doesn't fail all of them. Languages which still fail
+// are left out of the results, and their errors are returned with the results of
+// the others.
+func translateStyled(
+	ctx context.Context,
+	log *slog.Logger,
+	t translator.Translator,
+	from translator.Language,
+	to map[translator.Options][]translator.Language,
+	texts []string,
+) (map[translator.Options]map[translator.Language]translator.BatchResult, error) {
+	res := make(map[translator.Options]map[translator.Language]translator.BatchResult, len(to))
+	var errs []error
+	for o, ls := range to {
+		r, err := translateBatch(ctx, log, t, from, ls, texts, o)
+		if err == nil {
+			res[o] = r
+			continue
+		} else if len(ls) == 1 {
+			errs = append(errs, err)
+			continue
+		}
+
+		log.Debug("Failed to translate batch, translating each language",
+			slog.String("err", err.Error()),
+		)
+		res[o] = make(map[translator.Language]translator.BatchResult, len(ls))
+		for _, l := range ls {
+			r, err := translateBatch(ctx, log, t, from, []translator.Language{l}, texts, o)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			res[o][l] = r[l]
+		}
+	}
+	return res, e.Join(errs...)
 }
 
 func getUserWebhook(s *dgo.Session, channelID string, user *dgo.User) (*dgo.Webhook, error) {
@@ -414,18 +694,23 @@ func getTranslatedMessage(
 	db gconf.DB,
 	m, original *dgo.Message,
 	lang translator.Language,
+	provider string,
 ) (guilddb.Message, error) {
 	msg, err := db.Message(m.GuildID, m.ChannelID, m.ID)
 
 	if e.Is(err, guilddb.ErrNotFound) {
-		if err := db.MessageInsert(guilddb.NewTranslatedMessage(
+		tm := guilddb.NewTranslatedMessage(
 			m.GuildID,
 			m.ChannelID,
 			m.ID,
 			lang,
 			original.ChannelID,
 			original.ID,
-		)); err != nil {
+		)
+		if provider != "" {
+			tm.Provider = &provider
+		}
+		if err := db.MessageInsert(tm); err != nil {
 			return guilddb.Message{}, err
 		}
 		msg, err = db.Message(m.GuildID, m.ChannelID, m.ID)
@@ -0,0 +1,162 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+
+	"forge.capytal.company/capytal/dislate/bot/gconf"
+	"forge.capytal.company/capytal/dislate/translator"
+
+	gdb "forge.capytal.company/capytal/dislate/guilddb"
+
+	dgo "github.com/bwmarrin/discordgo"
+)
+
+const guildID = "1"
+
+// A stand-in for the parts of the Discord API used to send translated messages,
+// recording the contents sent to each channel through webhooks.
+type fakeDiscord struct {
+	mu       sync.Mutex
+	messages map[string][]string
+	nextID   int
+}
+
+func newFakeDiscord(t *testing.T) (*dgo.Session, *fakeDiscord) {
+	t.Helper()
+
+	d := &fakeDiscord{messages: make(map[string][]string), nextID: 900}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v9/channels/{id}", func(w http.ResponseWriter, r *http.Request) {
+		respond(t, w, dgo.Channel{
+			ID:      r.PathValue("id"),
+			GuildID: guildID,
+			Type:    dgo.ChannelTypeGuildText,
+		})
+	})
+	mux.HandleFunc("GET /api/v9/channels/{id}/webhooks", func(
+		w http.ResponseWriter,
+		r *http.Request,
+	) {
+		respond(t, w, []dgo.Webhook{})
+	})
+	mux.HandleFunc("POST /api/v9/channels/{id}/webhooks", func(
+		w http.ResponseWriter,
+		r *http.Request,
+	) {
+		// The webhook's ID is the channel's, so messages can be traced back to it
+		respond(t, w, dgo.Webhook{ID: r.PathValue("id"), Token: "token"})
+	})
+	mux.HandleFunc("POST /api/v9/webhooks/{id}/{token}", func(
+		w http.ResponseWriter,
+		r *http.Request,
+	) {
+		var p dgo.WebhookParams
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			t.Errorf("Failed to decode webhook params: %s", err)
+		}
+
+		d.mu.Lock()
+		defer d.mu.Unlock()
+
+		channelID := r.PathValue("id")
+		d.messages[channelID] = append(d.messages[channelID], p.Content)
+		d.nextID++
+
+		respond(t, w, dgo.Message{
+			ID:        strconv.Itoa(d.nextID),
+			ChannelID: channelID,
+			Content:   p.Content,
+		})
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	s, err := dgo.New("Bot token")
+	if err != nil {
+		t.Fatalf("Failed to create session: %s", err)
+	}
+	u, _ := url.Parse(srv.URL)
+	s.Client = &http.Client{Transport: redirectTransport{u}}
+
+	return s, d
+}
+
+// Sends all requests to the host, instead of Discord's.
+type redirectTransport struct {
+	host *url.URL
+}
+
+func (t redirectTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.URL.Scheme = t.host.Scheme
+	r.URL.Host = t.host.Host
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func respond(t *testing.T, w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		t.Errorf("Failed to encode response: %s", err)
+	}
+}
+
+func TestMessageCreateOffline(t *testing.T) {
+	s, discord := newFakeDiscord(t)
+
+	db := gdb.NewMemoryDB[gconf.ConfigString]()
+	if err := db.GuildInsert(gdb.NewGuild(guildID, gconf.NewConfig())); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	pt := gdb.NewChannel(guildID, "200", translator.PT)
+	en := gdb.NewChannel(guildID, "201", translator.EN)
+	for _, c := range []gdb.Channel{pt, en} {
+		if err := db.ChannelInsert(c); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	}
+	if err := db.ChannelGroupInsert(gdb.ChannelGroup{pt, en}); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	offline := translator.NewOffline(
+		translator.DefaultPhraseTable(),
+		translator.DefaultTrigramDetector(),
+		true,
+	)
+	h := NewMessageCreate(context.Background(), db, offline)
+
+	everr := h.Serve(s, &dgo.MessageCreate{Message: &dgo.Message{
+		ID:        "300",
+		ChannelID: pt.ID,
+		GuildID:   guildID,
+		Content:   "Olá, bom dia",
+		Type:      dgo.MessageTypeDefault,
+		Author:    &dgo.User{ID: "400", GlobalName: "Ana"},
+	}})
+	if everr != nil {
+		t.Fatalf("Unexpected error: %s", everr)
+	}
+
+	expected := "[pt→en] Hello, good morning"
+	if ms := discord.messages[en.ID]; len(ms) != 1 || ms[0] != expected {
+		t.Fatalf("Expected %q sent to the English channel, got %q", expected, ms)
+	} else if ms := discord.messages[pt.ID]; len(ms) != 0 {
+		t.Fatalf("Expected nothing sent to the original channel, got %q", ms)
+	}
+
+	tms, err := db.MessagesWithOrigin(guildID, pt.ID, "300")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	} else if len(tms) != 1 || tms[0].ChannelID != en.ID || tms[0].Language != translator.EN {
+		t.Fatalf("Expected the translated message in the English channel, got %+v", tms)
+	}
+}
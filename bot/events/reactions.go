@@ -0,0 +1,123 @@
+package events
+
+import (
+	e "errors"
+	"log/slog"
+
+	"forge.capytal.company/capytal/dislate/bot/events/errors"
+	"forge.capytal.company/capytal/dislate/bot/gconf"
+
+	gdb "forge.capytal.company/capytal/dislate/guilddb"
+
+	dgo "github.com/bwmarrin/discordgo"
+)
+
+type MessageReactionAdd struct {
+	db gconf.DB
+}
+
+func NewMessageReactionAdd(db gconf.DB) MessageReactionAdd {
+	return MessageReactionAdd{db}
+}
+
+func (h MessageReactionAdd) Serve(s *dgo.Session, ev *dgo.MessageReactionAdd) errors.EventErr {
+	if ev.GuildID == "" || ev.UserID == s.State.User.ID ||
+		(ev.Member != nil && ev.Member.User != nil && ev.Member.User.Bot) {
+		return nil
+	}
+	if !isEmoji(ev.Emoji, gconf.GetFlagEmoji(ev.GuildID, h.db)) {
+		return nil
+	}
+
+	log := gconf.GetLogger(ev.GuildID, s, h.db)
+	everr := errors.NewReactionErr[*dgo.MessageReactionAdd](ev.MessageReaction, log)
+
+	f, err := getTranslationFlag(h.db, ev.MessageReaction)
+	if e.Is(err, gdb.ErrNotFound) {
+		log.Debug("Flagged message is not a translation, ignoring.",
+			slog.String("guild", ev.GuildID),
+			slog.String("channel", ev.ChannelID),
+			slog.String("message", ev.MessageID),
+		)
+		return nil
+	} else if err != nil {
+		return everr.Join(e.New("Failed to get flagged message from database"), err)
+	}
+
+	err = h.db.TranslationFlagInsert(f)
+	if err != nil && !e.Is(err, gdb.ErrNoAffect) {
+		return everr.Join(e.New("Failed to add translation flag to database"), err)
+	}
+
+	log.Info("Translation flagged",
+		slog.String("channel", f.ChannelID),
+		slog.String("message", f.MessageID),
+		slog.String("user", f.UserID),
+		slog.String("from", string(f.From)),
+		slog.String("to", string(f.To)),
+		slog.String("provider", f.Provider),
+	)
+
+	return nil
+}
+
+type MessageReactionRemove struct {
+	db gconf.DB
+}
+
+func NewMessageReactionRemove(db gconf.DB) MessageReactionRemove {
+	return MessageReactionRemove{db}
+}
+
+func (h MessageReactionRemove) Serve(
+	s *dgo.Session,
+	ev *dgo.MessageReactionRemove,
+) errors.EventErr {
+	if ev.GuildID == "" || !isEmoji(ev.Emoji, gconf.GetFlagEmoji(ev.GuildID, h.db)) {
+		return nil
+	}
+
+	log := gconf.GetLogger(ev.GuildID, s, h.db)
+	everr := errors.NewReactionErr[*dgo.MessageReactionRemove](ev.MessageReaction, log)
+
+	f, err := getTranslationFlag(h.db, ev.MessageReaction)
+	if e.Is(err, gdb.ErrNotFound) {
+		return nil
+	} else if err != nil {
+		return everr.Join(e.New("Failed to get flagged message from database"), err)
+	}
+
+	err = h.db.TranslationFlagDelete(f)
+	if err != nil && !e.Is(err, gdb.ErrNoAffect) {
+		return everr.Join(e.New("Failed to remove translation flag from database"), err)
+	}
+
+	return nil
+}
+
+// Returns the flag of the reacted message, or ErrNotFound if the message is not
+// a translation.
+func getTranslationFlag(db gconf.DB, r *dgo.MessageReaction) (gdb.TranslationFlag, error) {
+	m, err := db.Message(r.GuildID, r.ChannelID, r.MessageID)
+	if err != nil {
+		return gdb.TranslationFlag{}, err
+	} else if m.OriginChannelID == nil || m.OriginID == nil {
+		return gdb.TranslationFlag{}, gdb.ErrNotFound
+	}
+
+	origin, err := db.Message(m.GuildID, *m.OriginChannelID, *m.OriginID)
+	if err != nil {
+		return gdb.TranslationFlag{}, err
+	}
+
+	return gdb.NewTranslationFlag(m, origin, r.UserID), nil
+}
+
+// Custom emojis may be configured by their name, as in messages (<:name:id>)
+// or as in reactions (name:id).
+func isEmoji(emoji dgo.Emoji, s string) bool {
+	if emoji.ID == "" {
+		return emoji.Name == s
+	}
+	return emoji.MessageFormat() == s || emoji.APIName() == s || emoji.Name == s
+}
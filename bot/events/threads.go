@@ -1,6 +1,7 @@
 package events
 
 import (
+	"context"
 	e "errors"
 	"log/slog"
 	"slices"
@@ -10,18 +11,19 @@ import (
 	"forge.capytal.company/capytal/dislate/bot/gconf"
 	"forge.capytal.company/capytal/dislate/translator"
 
-	gdb "dislate/internals/guilddb"
+	gdb "forge.capytal.company/capytal/dislate/guilddb"
 
 	dgo "github.com/bwmarrin/discordgo"
 )
 
 type EThreadCreate struct {
+	ctx        context.Context
 	db         gconf.DB
 	translator translator.Translator
 }
 
-func NewEThreadCreate(db gconf.DB, t translator.Translator) EThreadCreate {
-	return EThreadCreate{db, t}
+func NewEThreadCreate(ctx context.Context, db gconf.DB, t translator.Translator) EThreadCreate {
+	return EThreadCreate{ctx, db, t}
 }
 
 func (h EThreadCreate) Serve(s *dgo.Session, ev *dgo.ThreadCreate) errors.EventErr {
@@ -125,10 +127,14 @@ func (h EThreadCreate) Serve(s *dgo.Session, ev *dgo.ThreadCreate) errors.EventE
 					return
 				}
 
-				content, err := h.translator.Translate(
+				content, err := translate(
+					h.ctx,
+					log,
+					h.translator,
 					parentCh.Language,
 					pc.Language,
 					startMsg.Content,
+					pc.Options,
 				)
 				if err != nil {
 					errs <- everr.Join(e.New("Failed to translate forum post of thread"), err)
@@ -301,7 +307,7 @@ func (h EThreadCreate) Serve(s *dgo.Session, ev *dgo.ThreadCreate) errors.EventE
 		}
 		if m.Content != "" {
 			m.GuildID = th.GuildID
-			NewMessageCreate(h.db, h.translator).sendMessage(log, s, m)
+			NewMessageCreate(h.ctx, h.db, h.translator).sendMessage(log, s, m)
 		}
 	}
 
@@ -309,21 +315,27 @@ func (h EThreadCreate) Serve(s *dgo.Session, ev *dgo.ThreadCreate) errors.EventE
 }
 
 type ThreadCreate struct {
+	ctx        context.Context
 	db         gconf.DB
 	translator translator.Translator
 	session    *dgo.Session
 	thread     *dgo.Channel
 	originLang translator.Language
+	names      map[translator.Language]translator.BatchResult
 }
 
-func NewThreadCreate(db gconf.DB, t translator.Translator) ThreadCreate {
-	return ThreadCreate{db, t, nil, nil, translator.EN}
+func NewThreadCreate(ctx context.Context, db gconf.DB, t translator.Translator) ThreadCreate {
+	return ThreadCreate{ctx, db, t, nil, nil, translator.EN, nil}
 }
 
 func (h ThreadCreate) Serve(s *dgo.Session, ev *dgo.ThreadCreate) errors.EventErr {
 	log := gconf.GetLogger(ev.GuildID, s, h.db)
 	everr := errors.NewThreadCreateErr(s, ev, log)
 
+	if !gconf.GetEffectiveConfig(ev.GuildID, h.db).TranslateThreads {
+		return nil
+	}
+
 	parentCh, err := h.db.Channel(ev.GuildID, ev.ParentID)
 	if e.Is(err, gdb.ErrNotFound) {
 		log.Debug("Parent channel of thread not in database, ignoring",
@@ -344,12 +356,11 @@ func (h ThreadCreate) Serve(s *dgo.Session, ev *dgo.ThreadCreate) errors.EventEr
 
 	// INFO: Threads have the same ID as their starter messages
 	starterMsg, err := h.db.Message(parentCh.GuildID, parentCh.ID, ev.ID)
-	if e.Is(err, gdb.ErrNotFound) {
-		starterMsg = gdb.NewMessage(parentCh.GuildID, ev.ID, ev.ID, parentCh.Language)
-		err = h.db.MessageInsert(starterMsg)
-		if err != nil {
-			return everr.Join(e.New("Failed to add starter message to database"), err)
-		}
+	newStarterMsg := e.Is(err, gdb.ErrNotFound)
+	if newStarterMsg {
+		starterMsg = gdb.NewMessage(parentCh.GuildID, parentCh.ID, ev.ID, parentCh.Language)
+	} else if err != nil {
+		return everr.Join(e.New("Failed to get starter message from database"), err)
 	}
 
 	thread, err := s.Channel(starterMsg.ID)
@@ -369,13 +380,37 @@ func (h ThreadCreate) Serve(s *dgo.Session, ev *dgo.ThreadCreate) errors.EventEr
 		return everr.Join(e.New("Failed to get parent channel group"))
 	}
 
-	var wg sync.WaitGroup
-	tg := make(chan gdb.Channel)
-	errs := make(chan error)
+	var langs []translator.Language
+	for _, pc := range parentChannelGroup {
+		if pc.Language != parentCh.Language && !slices.Contains(langs, pc.Language) {
+			langs = append(langs, pc.Language)
+		}
+	}
+
+	names, err := translateBatch(
+		h.ctx,
+		log,
+		h.translator,
+		parentCh.Language,
+		langs,
+		[]string{thread.Name},
+		translator.Options{},
+	)
+	if err != nil {
+		return everr.Join(e.New("Failed to translate thread name"), err)
+	}
 
 	h.session = s
 	h.originLang = parentCh.Language
 	h.thread = thread
+	h.names = names
+
+	// Threads are created concurrently, and only added to the database after all
+	// of them are, so a failure doesn't leave a partial group behind.
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	threadGroup := gdb.ChannelGroup{gdb.NewChannel(thread.GuildID, thread.ID, parentCh.Language)}
+	everrs := []error{}
 
 	for _, pc := range parentChannelGroup {
 		if pc.ID == ev.ParentID {
@@ -383,44 +418,56 @@ func (h ThreadCreate) Serve(s *dgo.Session, ev *dgo.ThreadCreate) errors.EventEr
 		}
 
 		wg.Add(1)
-		go func(tg chan<- gdb.Channel, errs chan<- error) {
+		go func(pc gdb.Channel) {
 			defer wg.Done()
 			t, err := h.startTranslatedThread(pc, starterMsg)
-			tg <- t
-			if err != nil {
-				errs <- err
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if e.Is(err, gdb.ErrNotFound) {
+				log.Debug("No translated starter message in channel, ignoring",
+					slog.String("ThreadID", ev.ID),
+					slog.String("ChannelID", pc.ID))
+			} else if err != nil {
+				everrs = append(everrs, err)
+			} else {
+				threadGroup = append(threadGroup, t)
 			}
-			log.Debug("FINISHED")
-		}(tg, errs)
+		}(pc)
 	}
 
 	wg.Wait()
 
-	everrs := make([]error, 0, len(errs))
-	for err := range errs {
-		log.Debug("ERR")
-		everrs = append(everrs, err)
-	}
-	if len(errs) > 0 {
-		log.Debug("ERR RETURN")
+	if len(everrs) > 0 {
 		return everr.Join(everrs...)
 	}
 
-	log.Debug("FUNCTION 1")
+	err = h.db.Tx(func(tx gdb.GuildDB[gconf.ConfigString]) error {
+		if newStarterMsg {
+			if err := tx.MessageInsert(starterMsg); err != nil {
+				return e.Join(e.New("Failed to add starter message to database"), err)
+			}
+		}
 
-	if err := h.db.ChannelInsert(gdb.NewChannel(thread.GuildID, thread.ID, parentCh.Language)); err != nil {
-		return everr.Join(e.New("Failed to add thread channel to database"), err)
-	}
+		for _, c := range threadGroup {
+			if err := tx.ChannelInsert(c); err != nil {
+				return e.Join(e.New("Failed to add thread channel to database"), err)
+			}
+		}
 
-	threadGroup := make(gdb.ChannelGroup, 0, len(tg))
-	for t := range tg {
-		threadGroup = append(threadGroup, t)
-	}
+		if len(threadGroup) < 2 {
+			return nil
+		}
 
-	log.Debug("FUNCTION 2")
+		if err := tx.ChannelGroupInsert(threadGroup); err != nil {
+			return e.Join(e.New("Failed to add group of thread to database"), err)
+		}
 
-	if err := h.db.ChannelGroupInsert(threadGroup); err != nil {
-		return everr.Join(e.New("Failed to add group of thread to database"), err)
+		return nil
+	})
+	if err != nil {
+		return everr.Join(err)
 	}
 
 	thMsgs, err := s.ChannelMessages(thread.ID, 10, "", "", "")
@@ -428,11 +475,9 @@ func (h ThreadCreate) Serve(s *dgo.Session, ev *dgo.ThreadCreate) errors.EventEr
 		return everr.Join(e.New("Failed to get thread messages"), err)
 	}
 
-	log.Debug("FUNCTION 3")
-
 	for _, m := range thMsgs {
 		m.GuildID = thread.GuildID
-		err := NewMessageCreate(h.db, h.translator).sendMessage(log, s, m)
+		err := NewMessageCreate(h.ctx, h.db, h.translator).sendMessage(log, s, m)
 		if err != nil {
 			return everr.Join(e.New("Failed to translate thread messages"), err)
 		}
@@ -457,8 +502,7 @@ func (h ThreadCreate) startTranslatedThread(
 	}
 
 	m, err := h.db.MessageWithOriginByLang(sm.GuildID, sm.ChannelID, sm.ID, pc.Language)
-	if e.Is(err, gdb.ErrNotFound) {
-	} else if err != nil {
+	if err != nil {
 		return gdb.Channel{}, e.Join(
 			e.New("Failed to get translated message of starter message"),
 			err,
@@ -471,9 +515,9 @@ func (h ThreadCreate) startTranslatedThread(
 func (h ThreadCreate) startTranslatedMessageThread(
 	m gdb.Message,
 ) (gdb.Channel, error) {
-	name, err := h.translator.Translate(h.originLang, m.Language, h.thread.Name)
-	if err != nil {
-		return gdb.Channel{}, e.Join(e.New("Failed to translate thread name"), err)
+	name := h.thread.Name
+	if n, ok := h.names[m.Language]; ok {
+		name = n.Texts[0]
 	}
 
 	th, err := h.session.MessageThreadStartComplex(m.ChannelID, m.ID, &dgo.ThreadStart{
@@ -488,10 +532,5 @@ func (h ThreadCreate) startTranslatedMessageThread(
 		return gdb.Channel{}, e.Join(e.New("Failed to create thread"), err)
 	}
 
-	c := gdb.NewChannel(th.GuildID, th.ID, m.Language)
-	if err := h.db.ChannelInsert(c); err != nil {
-		return c, e.Join(e.New("Failed to insert thread on database"), err)
-	}
-
-	return c, nil
+	return gdb.NewChannel(th.GuildID, th.ID, m.Language), nil
 }
@@ -1,7 +1,16 @@
 package gconf
 
 import (
+	"encoding/json"
+	e "errors"
+	"fmt"
 	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"forge.capytal.company/capytal/dislate/translator"
 
 	gdb "forge.capytal.company/capytal/dislate/guilddb"
 
@@ -12,9 +21,170 @@ type Config struct {
 	Logger *slog.Logger
 }
 
+// Stored configuration of a guild. Nil fields aren't configured, so the
+// defaults are used, see ConfigString.Effective.
 type ConfigString struct {
+	// Version of the config's schema, older configs are upgraded when decoded.
+	Version int `json:"version"`
+
 	LoggingChannel *string     `json:"logging_channel"`
 	LoggingLevel   *slog.Level `json:"logging_level"`
+	// Reaction used to flag bad translations.
+	FlagEmoji *string `json:"flag_emoji"`
+	// Days messages are kept in the database before being pruned, 0 keeps them
+	// forever. Messages are kept forever by default, pruning is opt-in.
+	MessageRetentionDays *int `json:"message_retention_days"`
+
+	// Language of channels added to the database by commands, before it is set.
+	DefaultLanguage *translator.Language `json:"default_language"`
+	// Detect the language of messages in channels added by commands.
+	DefaultAutoDetect *bool `json:"default_auto_detect"`
+
+	// Username of translated messages, "{user}" is replaced by the author's name
+	// and "{lang}" by the language code of the translation.
+	WebhookUsername *string `json:"webhook_username"`
+
+	// Translate edits of messages.
+	TranslateEdits *bool `json:"translate_edits"`
+	// Create translated threads when a thread is created in a linked channel.
+	TranslateThreads *bool `json:"translate_threads"`
+
+	// Messages longer than this aren't translated.
+	MaxMessageLength *int `json:"max_message_length"`
+}
+
+// Current version of ConfigString.
+const ConfigVersion = 1
+
+const (
+	DefaultFlagEmoji            = "👎"
+	DefaultMessageRetentionDays = 0
+	DefaultWebhookUsername      = "{user}"
+	DefaultMaxMessageLength     = 2000
+
+	maxMessageRetentionDays = 10 * 365
+	maxMessageLength        = 4000
+	// Limit of Discord for webhook usernames.
+	maxWebhookUsernameLength = 80
+)
+
+// Returns a config with no options configured.
+func NewConfig() ConfigString {
+	return ConfigString{Version: ConfigVersion}
+}
+
+// Configs without a version were stored before versioning, and have the same
+// fields as the first version. Configs of newer versions are rejected, as
+// their options can't be kept when the config is stored again.
+func (c *ConfigString) UnmarshalJSON(b []byte) error {
+	type config ConfigString
+	var v config
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	if v.Version > ConfigVersion {
+		return fmt.Errorf("Config version %d is newer than %d", v.Version, ConfigVersion)
+	}
+	*c = ConfigString(v)
+
+	if c.Version < 1 {
+		c.Version = 1
+	}
+
+	return nil
+}
+
+// Validates the configured options, used by the database before storing the
+// config.
+func (c ConfigString) Validate() error {
+	var errs []error
+
+	if c.Version > ConfigVersion {
+		errs = append(errs, fmt.Errorf("version must be at most %d, got %d", ConfigVersion, c.Version))
+	}
+	if c.LoggingChannel != nil && *c.LoggingChannel == "" {
+		errs = append(errs, e.New("logging_channel must not be empty"))
+	}
+	if c.FlagEmoji != nil && strings.TrimSpace(*c.FlagEmoji) == "" {
+		errs = append(errs, e.New("flag_emoji must not be empty"))
+	}
+	if d := c.MessageRetentionDays; d != nil && (*d < 0 || *d > maxMessageRetentionDays) {
+		errs = append(errs, fmt.Errorf(
+			"message_retention_days must be between 0 and %d, got %d",
+			maxMessageRetentionDays,
+			*d,
+		))
+	}
+	if l := c.DefaultLanguage; l != nil && !l.IsValid() {
+		errs = append(errs, fmt.Errorf("default_language %q is not a supported language", *l))
+	}
+	if u := c.WebhookUsername; u != nil {
+		n := utf8.RuneCountInString(*u)
+		if strings.TrimSpace(*u) == "" || n > maxWebhookUsernameLength {
+			errs = append(errs, fmt.Errorf(
+				"webhook_username must have between 1 and %d characters",
+				maxWebhookUsernameLength,
+			))
+		} else if strings.Contains(strings.ToLower(*u), "discord") {
+			errs = append(errs, e.New("webhook_username must not contain \"discord\""))
+		}
+	}
+	if l := c.MaxMessageLength; l != nil && (*l < 1 || *l > maxMessageLength) {
+		errs = append(errs, fmt.Errorf(
+			"max_message_length must be between 1 and %d, got %d",
+			maxMessageLength,
+			*l,
+		))
+	}
+
+	return e.Join(errs...)
+}
+
+// Configuration of a guild with the defaults of options which aren't configured.
+type EffectiveConfig struct {
+	// Empty if logging is disabled.
+	LoggingChannel       string
+	LoggingLevel         slog.Level
+	FlagEmoji            string
+	MessageRetentionDays int
+	DefaultLanguage      translator.Language
+	DefaultAutoDetect    bool
+	WebhookUsername      string
+	TranslateEdits       bool
+	TranslateThreads     bool
+	MaxMessageLength     int
+}
+
+// Returns the config with defaults applied to the options not configured.
+func (c ConfigString) Effective() EffectiveConfig {
+	return EffectiveConfig{
+		LoggingChannel:       orDefault(c.LoggingChannel, ""),
+		LoggingLevel:         orDefault(c.LoggingLevel, slog.LevelInfo),
+		FlagEmoji:            orDefault(c.FlagEmoji, DefaultFlagEmoji),
+		MessageRetentionDays: orDefault(c.MessageRetentionDays, DefaultMessageRetentionDays),
+		DefaultLanguage:      orDefault(c.DefaultLanguage, translator.EN),
+		DefaultAutoDetect:    orDefault(c.DefaultAutoDetect, false),
+		WebhookUsername:      orDefault(c.WebhookUsername, DefaultWebhookUsername),
+		TranslateEdits:       orDefault(c.TranslateEdits, true),
+		TranslateThreads:     orDefault(c.TranslateThreads, true),
+		MaxMessageLength:     orDefault(c.MaxMessageLength, DefaultMaxMessageLength),
+	}
+}
+
+// Returns the username of a translated message of the user.
+func (c EffectiveConfig) Username(user string, lang translator.Language) string {
+	u := strings.NewReplacer("{user}", user, "{lang}", string(lang)).Replace(c.WebhookUsername)
+	if r := []rune(u); len(r) > maxWebhookUsernameLength {
+		u = string(r[:maxWebhookUsernameLength])
+	}
+	return u
+}
+
+func orDefault[T any](v *T, d T) T {
+	if v == nil {
+		return d
+	}
+	return *v
 }
 
 type (
@@ -26,20 +196,15 @@ func (g Guild) GetConfig(s *dgo.Session) (*Config, error) {
 	var l *slog.Logger
 	var err error
 
-	if g.Config.LoggingChannel != nil {
-		c, err := s.Channel(*g.Config.LoggingChannel)
+	ec := g.Config.Effective()
+	if ec.LoggingChannel != "" {
+		c, err := s.Channel(ec.LoggingChannel)
 		if err != nil {
 			return nil, err
 		}
 
-		var lv slog.Level
-		if g.Config.LoggingLevel != nil {
-			lv = *g.Config.LoggingLevel
-		} else {
-			lv = slog.LevelInfo
-		}
 		l = slog.New(NewGuildHandler(s, c, &slog.HandlerOptions{
-			Level: lv,
+			Level: ec.LoggingLevel,
 		}))
 	} else {
 		l = slog.New(disabledHandler{})
@@ -61,3 +226,137 @@ func GetLogger(guildID string, s *dgo.Session, db DB) *slog.Logger {
 
 	return c.Logger
 }
+
+// Returns the effective config of the guild, or the defaults if the guild
+// isn't found.
+func GetEffectiveConfig(guildID string, db DB) EffectiveConfig {
+	g, err := db.Guild(guildID)
+	if err != nil {
+		return NewConfig().Effective()
+	}
+	return g.Config.Effective()
+}
+
+// Returns the reaction used to flag bad translations in the guild.
+func GetFlagEmoji(guildID string, db DB) string {
+	return GetEffectiveConfig(guildID, db).FlagEmoji
+}
+
+// Returns how long messages of the guild are kept in the database, or 0 if
+// they are never pruned.
+func GetMessageRetention(guildID string, db DB) time.Duration {
+	days := GetEffectiveConfig(guildID, db).MessageRetentionDays
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// An option of the config as shown to users.
+type ConfigOption struct {
+	Name  string
+	Value string
+	// If the option isn't configured, so Value is the default.
+	Default bool
+}
+
+// Names of the options which can be changed with ConfigString.Set.
+var ConfigOptionNames = []string{
+	"logging_channel",
+	"logging_level",
+	"flag_emoji",
+	"message_retention_days",
+	"default_language",
+	"default_auto_detect",
+	"webhook_username",
+	"translate_edits",
+	"translate_threads",
+	"max_message_length",
+}
+
+// Returns the effective value of each option, in the order of ConfigOptionNames.
+func (c ConfigString) Options() []ConfigOption {
+	ec := c.Effective()
+
+	channel := "disabled"
+	if ec.LoggingChannel != "" {
+		channel = "<#" + ec.LoggingChannel + ">"
+	}
+
+	return []ConfigOption{
+		{"logging_channel", channel, c.LoggingChannel == nil},
+		{"logging_level", ec.LoggingLevel.String(), c.LoggingLevel == nil},
+		{"flag_emoji", ec.FlagEmoji, c.FlagEmoji == nil},
+		{
+			"message_retention_days",
+			strconv.Itoa(ec.MessageRetentionDays),
+			c.MessageRetentionDays == nil,
+		},
+		{"default_language", ec.DefaultLanguage.Name(), c.DefaultLanguage == nil},
+		{"default_auto_detect", strconv.FormatBool(ec.DefaultAutoDetect), c.DefaultAutoDetect == nil},
+		{"webhook_username", ec.WebhookUsername, c.WebhookUsername == nil},
+		{"translate_edits", strconv.FormatBool(ec.TranslateEdits), c.TranslateEdits == nil},
+		{"translate_threads", strconv.FormatBool(ec.TranslateThreads), c.TranslateThreads == nil},
+		{"max_message_length", strconv.Itoa(ec.MaxMessageLength), c.MaxMessageLength == nil},
+	}
+}
+
+// Parses and sets the value of an option, "default" resets it to the default
+// value. The config isn't validated, see ConfigString.Validate.
+func (c *ConfigString) Set(name, value string) error {
+	value = strings.TrimSpace(value)
+	reset := strings.EqualFold(value, "default")
+
+	// Options are set in a copy, so the config isn't changed on errors
+	n := *c
+
+	var err error
+	switch name {
+	case "logging_channel":
+		n.LoggingChannel, err = parseOption(reset, value, func(v string) (string, error) {
+			return strings.TrimSuffix(strings.TrimPrefix(v, "<#"), ">"), nil
+		})
+	case "logging_level":
+		n.LoggingLevel, err = parseOption(reset, value, func(v string) (slog.Level, error) {
+			var l slog.Level
+			return l, l.UnmarshalText([]byte(v))
+		})
+	case "flag_emoji":
+		n.FlagEmoji, err = parseOption(reset, value, parseString)
+	case "message_retention_days":
+		n.MessageRetentionDays, err = parseOption(reset, value, strconv.Atoi)
+	case "default_language":
+		n.DefaultLanguage, err = parseOption(reset, value, translator.ParseLanguage)
+	case "default_auto_detect":
+		n.DefaultAutoDetect, err = parseOption(reset, value, strconv.ParseBool)
+	case "webhook_username":
+		n.WebhookUsername, err = parseOption(reset, value, parseString)
+	case "translate_edits":
+		n.TranslateEdits, err = parseOption(reset, value, strconv.ParseBool)
+	case "translate_threads":
+		n.TranslateThreads, err = parseOption(reset, value, strconv.ParseBool)
+	case "max_message_length":
+		n.MaxMessageLength, err = parseOption(reset, value, strconv.Atoi)
+	default:
+		return fmt.Errorf("Unknown config option %q", name)
+	}
+
+	if err != nil {
+		return e.Join(fmt.Errorf("Invalid value %q for option %s", value, name), err)
+	}
+
+	*c = n
+	return nil
+}
+
+func parseOption[T any](reset bool, value string, parse func(string) (T, error)) (*T, error) {
+	if reset {
+		return nil, nil
+	}
+	v, err := parse(value)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func parseString(v string) (string, error) {
+	return v, nil
+}
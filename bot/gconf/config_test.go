@@ -0,0 +1,204 @@
+package gconf
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"forge.capytal.company/capytal/dislate/translator"
+
+	gdb "forge.capytal.company/capytal/dislate/guilddb"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+var configBlobs = []struct {
+	name     string
+	blob     string
+	expected ConfigString
+	newer    bool
+}{
+	{
+		name:     "old",
+		blob:     `{"flag_emoji":"🚩","logging_channel":"200"}`,
+		expected: ConfigString{Version: 1, FlagEmoji: ptr("🚩"), LoggingChannel: ptr("200")},
+	},
+	{
+		name:     "current",
+		blob:     `{"version":1,"translate_edits":false,"max_message_length":500}`,
+		expected: ConfigString{Version: 1, TranslateEdits: ptr(false), MaxMessageLength: ptr(500)},
+	},
+	{
+		name:  "newer",
+		blob:  `{"version":2,"flag_emoji":"🚩","new_option":true}`,
+		newer: true,
+	},
+}
+
+func TestConfigUnmarshal(t *testing.T) {
+	for _, test := range configBlobs {
+		t.Run(test.name, func(t *testing.T) {
+			var c ConfigString
+			err := json.Unmarshal([]byte(test.blob), &c)
+			if test.newer {
+				if err == nil {
+					t.Fatalf("Expected an error decoding a newer config, got %+v", c)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			} else if !reflect.DeepEqual(c, test.expected) {
+				t.Fatalf("Expected config %+v, got %+v", test.expected, c)
+			}
+		})
+	}
+}
+
+func TestConfigDatabase(t *testing.T) {
+	file := "file:" + filepath.Join(t.TempDir(), "guild.db")
+
+	// Blobs are stored as they are, as if stored by another version of the bot
+	raw, err := gdb.NewSQLiteDB[json.RawMessage](file)
+	if err != nil {
+		t.Fatalf("Failed to open database: %s", err)
+	}
+	if err := raw.Prepare(); err != nil {
+		t.Fatalf("Failed to prepare database: %s", err)
+	}
+	for i, test := range configBlobs {
+		g := gdb.NewGuild(strings.Repeat("1", i+1), json.RawMessage(test.blob))
+		if err := raw.GuildInsert(g); err != nil {
+			t.Fatalf("Failed to insert guild: %s", err)
+		}
+	}
+	if err := raw.Close(); err != nil {
+		t.Fatalf("Failed to close database: %s", err)
+	}
+
+	db, err := gdb.NewSQLiteDB[ConfigString](file)
+	if err != nil {
+		t.Fatalf("Failed to open database: %s", err)
+	}
+	defer db.Close()
+
+	for i, test := range configBlobs {
+		t.Run(test.name, func(t *testing.T) {
+			g, err := db.Guild(strings.Repeat("1", i+1))
+			if test.newer {
+				if !errors.Is(err, gdb.ErrConfigParsing) {
+					t.Fatalf("Expected error %q, got %v", gdb.ErrConfigParsing, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			} else if !reflect.DeepEqual(g.Config, test.expected) {
+				t.Fatalf("Expected config %+v, got %+v", test.expected, g.Config)
+			}
+		})
+	}
+
+	// Unreadable configs fall back to the defaults
+	if c := GetEffectiveConfig("111", db); c != NewConfig().Effective() {
+		t.Fatalf("Expected the default config, got %+v", c)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		config ConfigString
+	}{
+		{"newer", ConfigString{Version: ConfigVersion + 1}},
+		{"empty logging channel", ConfigString{LoggingChannel: ptr("")}},
+		{"empty flag emoji", ConfigString{FlagEmoji: ptr(" ")}},
+		{"negative retention", ConfigString{MessageRetentionDays: ptr(-1)}},
+		{"unknown language", ConfigString{DefaultLanguage: ptr(translator.Language("xx"))}},
+		{"long username", ConfigString{WebhookUsername: ptr(strings.Repeat("a", 81))}},
+		{"discord username", ConfigString{WebhookUsername: ptr("Discord {user}")}},
+		{"zero message length", ConfigString{MaxMessageLength: ptr(0)}},
+		{"long message length", ConfigString{MaxMessageLength: ptr(4001)}},
+	}
+
+	db := gdb.NewMemoryDB[ConfigString]()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.config.Validate(); err == nil {
+				t.Fatalf("Expected config %+v to be invalid", test.config)
+			}
+
+			// Invalid configs are never stored
+			err := db.GuildInsert(gdb.NewGuild("100", test.config))
+			if !errors.Is(err, gdb.ErrConfigParsing) {
+				t.Fatalf("Expected error %q, got %v", gdb.ErrConfigParsing, err)
+			}
+		})
+	}
+
+	if err := db.GuildInsert(gdb.NewGuild("100", NewConfig())); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+}
+
+func TestConfigEffective(t *testing.T) {
+	expected := EffectiveConfig{
+		LoggingChannel:       "",
+		LoggingLevel:         slog.LevelInfo,
+		FlagEmoji:            DefaultFlagEmoji,
+		MessageRetentionDays: DefaultMessageRetentionDays,
+		DefaultLanguage:      translator.EN,
+		DefaultAutoDetect:    false,
+		WebhookUsername:      DefaultWebhookUsername,
+		TranslateEdits:       true,
+		TranslateThreads:     true,
+		MaxMessageLength:     DefaultMaxMessageLength,
+	}
+	if c := NewConfig().Effective(); c != expected {
+		t.Fatalf("Expected defaults %+v, got %+v", expected, c)
+	}
+
+	c := NewConfig()
+	c.TranslateEdits = ptr(false)
+	c.DefaultLanguage = ptr(translator.PT)
+	expected.TranslateEdits = false
+	expected.DefaultLanguage = translator.PT
+	if ec := c.Effective(); ec != expected {
+		t.Fatalf("Expected config %+v, got %+v", expected, ec)
+	}
+}
+
+func TestConfigSet(t *testing.T) {
+	c := NewConfig()
+
+	if err := c.Set("max_message_length", "500"); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	} else if c.MaxMessageLength == nil || *c.MaxMessageLength != 500 {
+		t.Fatalf("Expected max_message_length to be 500, got %v", c.MaxMessageLength)
+	}
+
+	// Values which can't be parsed don't change the config
+	if err := c.Set("max_message_length", "many"); err == nil {
+		t.Fatalf("Expected an error setting an invalid value")
+	} else if *c.MaxMessageLength != 500 {
+		t.Fatalf("Expected max_message_length to not change, got %d", *c.MaxMessageLength)
+	}
+
+	if err := c.Set("max_message_length", "Default"); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	} else if c.MaxMessageLength != nil {
+		t.Fatalf("Expected max_message_length to be reset, got %d", *c.MaxMessageLength)
+	}
+
+	if err := c.Set("unknown", "1"); err == nil {
+		t.Fatalf("Expected an error setting an unknown option")
+	}
+}
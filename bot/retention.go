@@ -0,0 +1,88 @@
+package bot
+
+import (
+	"log/slog"
+	"time"
+
+	"forge.capytal.company/capytal/dislate/bot/gconf"
+)
+
+const (
+	// How often messages older than the retention of their guild are pruned.
+	pruneInterval = time.Hour
+	// Messages deleted in each transaction. Batches are kept small and spaced
+	// out so event handlers aren't blocked waiting for the database.
+	pruneBatchSize  = 200
+	pruneBatchDelay = 100 * time.Millisecond
+)
+
+// Prunes messages of all guilds every pruneInterval until the bot is stopped.
+// The first run waits an interval, so the guilds are loaded after connecting.
+func (b *Bot) pruneMessages() {
+	t := time.NewTicker(pruneInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-b.ctx.Done():
+			return
+		case <-t.C:
+		}
+
+		for _, id := range b.guildIDs() {
+			if b.ctx.Err() != nil {
+				return
+			}
+			b.pruneGuildMessages(id)
+		}
+	}
+}
+
+func (b *Bot) pruneGuildMessages(guildID string) {
+	retention := gconf.GetMessageRetention(guildID, b.db)
+	if retention <= 0 {
+		return
+	}
+	before := time.Now().Add(-retention)
+
+	total := 0
+	for {
+		n, err := b.db.MessagesPrune(guildID, before, pruneBatchSize)
+		if err != nil {
+			b.logger.Error("Failed to prune messages",
+				slog.String("guild", guildID),
+				slog.String("err", err.Error()),
+			)
+			return
+		}
+		total += n
+
+		if n < pruneBatchSize {
+			break
+		}
+
+		select {
+		case <-b.ctx.Done():
+			return
+		case <-time.After(pruneBatchDelay):
+		}
+	}
+
+	if total > 0 {
+		b.logger.Info("Pruned old messages",
+			slog.String("guild", guildID),
+			slog.Int("messages", total),
+		)
+	}
+}
+
+func (b *Bot) guildIDs() []string {
+	b.session.State.RLock()
+	defer b.session.State.RUnlock()
+
+	ids := make([]string, 0, len(b.session.State.Guilds))
+	for _, g := range b.session.State.Guilds {
+		ids = append(ids, g.ID)
+	}
+	return ids
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"forge.capytal.company/capytal/dislate/bot/gconf"
+	"forge.capytal.company/capytal/dislate/guilddb"
+)
+
+// Runs a subcommand instead of the bot, used to manage the database.
+func runCommand(db database, args []string) error {
+	switch args[0] {
+	case "export":
+		return exportCommand(db, args[1:])
+	case "import":
+		return importCommand(db, args[1:])
+	default:
+		return fmt.Errorf("Unknown command %q, available commands are export and import", args[0])
+	}
+}
+
+// Writes the export of a guild as JSON, usage: export [-messages] [-o file] <guild-id>
+func exportCommand(db database, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	messages := fs.Bool("messages", false, "Also export the mappings of translated messages")
+	output := fs.String("o", "-", "File to write the export to, - for stdout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	} else if fs.NArg() != 1 {
+		return errors.New("Expected the ID of the guild to export")
+	}
+
+	e, err := guilddb.ExportGuild[gconf.ConfigString](db, fs.Arg(0), *messages)
+	if err != nil {
+		return errors.Join(errors.New("Failed to export guild"), err)
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "-" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(e)
+}
+
+// Imports a JSON export of a guild, usage: import [-guild id] <file>
+func importCommand(db database, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	guild := fs.String("guild", "", "ID of the guild to import into, instead of the exported one")
+	if err := fs.Parse(args); err != nil {
+		return err
+	} else if fs.NArg() != 1 {
+		return errors.New("Expected the file to import, - for stdin")
+	}
+
+	var r io.Reader = os.Stdin
+	if fs.Arg(0) != "-" {
+		f, err := os.Open(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var e guilddb.GuildExport[gconf.ConfigString]
+	if err := json.NewDecoder(r).Decode(&e); err != nil {
+		return errors.Join(errors.New("Failed to decode export"), err)
+	}
+	if *guild != "" {
+		e.GuildID = *guild
+	}
+
+	if err := guilddb.ImportGuild[gconf.ConfigString](db, e); err != nil {
+		return errors.Join(errors.New("Failed to import guild"), err)
+	}
+	return nil
+}
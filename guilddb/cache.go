@@ -0,0 +1,111 @@
+package guilddb
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+func (db *SQLiteDB[C]) CacheGet(key string) (string, bool, error) {
+	var t string
+	err := db.conn().QueryRow(`
+		SELECT Translation FROM translationCache
+			WHERE "Key" = $1 AND "ExpiresAt" > $2
+	`, key, time.Now().Unix()).Scan(&t)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, nil
+	} else if err != nil {
+		return "", false, errors.Join(ErrInternal, err)
+	}
+
+	return t, true, nil
+}
+
+func (db *SQLiteDB[C]) CacheSet(key, translation string, expires time.Time) error {
+	_, err := db.conn().Exec(`
+		INSERT OR REPLACE INTO translationCache (Key, Translation, CreatedAt, ExpiresAt)
+			VALUES ($1, $2, $3, $4)
+	`, key, translation, time.Now().Unix(), expires.Unix())
+	if err != nil {
+		return errors.Join(ErrInternal, err)
+	}
+
+	return nil
+}
+
+func (db *SQLiteDB[C]) CacheTrim(max int) error {
+	if _, err := db.conn().Exec(`
+		DELETE FROM translationCache
+			WHERE "ExpiresAt" <= $1
+	`, time.Now().Unix()); err != nil {
+		return errors.Join(ErrInternal, err)
+	}
+
+	if _, err := db.conn().Exec(`
+		DELETE FROM translationCache
+			WHERE "Key" IN (
+				SELECT Key FROM translationCache
+					ORDER BY "CreatedAt" DESC
+					LIMIT -1 OFFSET $1
+			)
+	`, max); err != nil {
+		return errors.Join(ErrInternal, err)
+	}
+
+	return nil
+}
+
+func (db *PostgresDB[C]) CacheGet(key string) (string, bool, error) {
+	var t string
+	err := db.conn().QueryRow(`
+		SELECT Translation FROM translationCache
+			WHERE Key = $1 AND ExpiresAt > $2
+	`, key, time.Now().Unix()).Scan(&t)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, nil
+	} else if err != nil {
+		return "", false, errors.Join(ErrInternal, err)
+	}
+
+	return t, true, nil
+}
+
+func (db *PostgresDB[C]) CacheSet(key, translation string, expires time.Time) error {
+	_, err := db.conn().Exec(`
+		INSERT INTO translationCache (Key, Translation, CreatedAt, ExpiresAt)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (Key) DO UPDATE
+				SET Translation = excluded.Translation,
+					CreatedAt = excluded.CreatedAt,
+					ExpiresAt = excluded.ExpiresAt
+	`, key, translation, time.Now().Unix(), expires.Unix())
+	if err != nil {
+		return errors.Join(ErrInternal, err)
+	}
+
+	return nil
+}
+
+func (db *PostgresDB[C]) CacheTrim(max int) error {
+	if _, err := db.conn().Exec(`
+		DELETE FROM translationCache
+			WHERE ExpiresAt <= $1
+	`, time.Now().Unix()); err != nil {
+		return errors.Join(ErrInternal, err)
+	}
+
+	if _, err := db.conn().Exec(`
+		DELETE FROM translationCache
+			WHERE Key IN (
+				SELECT Key FROM translationCache
+					ORDER BY CreatedAt DESC
+					OFFSET $1
+			)
+	`, max); err != nil {
+		return errors.Join(ErrInternal, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package guilddb
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// Queries used by both SQLiteDB and PostgresDB to check the references of
+// Guilds and Channels. SQLite doesn't enforce foreign keys, so they are checked
+// before changing guilds and channels, for both databases to return the same
+// errors.
+
+func checkGuildExists(q querier, guildID string) error {
+	var n int
+	err := q.QueryRow(`
+		SELECT COUNT(*) FROM guilds
+			WHERE ID = $1
+	`, guildID).Scan(&n)
+
+	if err != nil {
+		return errors.Join(ErrInternal, err)
+	} else if n == 0 {
+		return errors.Join(
+			ErrPreconditionFailed,
+			fmt.Errorf("Guild %s doesn't exists in the database", guildID),
+		)
+	}
+
+	return nil
+}
+
+func checkGuildUnreferenced(tx *sql.Tx, guildID string) error {
+	var channels, groups, terms, flags int
+	err := tx.QueryRow(`
+		SELECT
+			(SELECT COUNT(*) FROM channels WHERE GuildID = $1),
+			(SELECT COUNT(*) FROM groups WHERE GuildID = $1),
+			(SELECT COUNT(*) FROM glossary WHERE GuildID = $1),
+			(SELECT COUNT(*) FROM translationFlags WHERE GuildID = $1)
+	`, guildID).Scan(&channels, &groups, &terms, &flags)
+
+	if err != nil {
+		return errors.Join(ErrInternal, err)
+	} else if channels > 0 || groups > 0 {
+		return errors.Join(ErrPreconditionFailed, fmt.Errorf("Guild %s has channels", guildID))
+	} else if terms > 0 {
+		return errors.Join(
+			ErrPreconditionFailed,
+			fmt.Errorf("Guild %s has glossary terms", guildID),
+		)
+	} else if flags > 0 {
+		return errors.Join(
+			ErrPreconditionFailed,
+			fmt.Errorf("Guild %s has flagged translations", guildID),
+		)
+	}
+
+	return nil
+}
+
+func checkChannelUnreferenced(tx *sql.Tx, c Channel) error {
+	var grouped, messages int
+	err := tx.QueryRow(`
+		SELECT
+			(SELECT COUNT(*) FROM group_members WHERE GuildID = $1 AND ChannelID = $2),
+			(SELECT COUNT(*) FROM messages WHERE GuildID = $1 AND ChannelID = $2)
+	`, c.GuildID, c.ID).Scan(&grouped, &messages)
+
+	if err != nil {
+		return errors.Join(ErrInternal, err)
+	} else if grouped > 0 {
+		return errors.Join(ErrPreconditionFailed, fmt.Errorf("Channel %s is in a group", c.ID))
+	} else if messages > 0 {
+		return errors.Join(ErrPreconditionFailed, fmt.Errorf("Channel %s has messages", c.ID))
+	}
+
+	return nil
+}
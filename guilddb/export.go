@@ -0,0 +1,251 @@
+package guilddb
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"forge.capytal.company/capytal/dislate/translator"
+)
+
+// Version of the GuildExport document, increased when a change would make older
+// versions of the bot import it incorrectly.
+const ExportVersion = 1
+
+// A versioned JSON document with the data of a Guild, used to back it up or move
+// it between databases and instances of the bot.
+type GuildExport[C any] struct {
+	Version  int             `json:"version"`
+	GuildID  string          `json:"guild_id"`
+	Config   C               `json:"config"`
+	Channels []ExportChannel `json:"channels"`
+	// Channel IDs of each ChannelGroup.
+	Groups   [][]string       `json:"groups"`
+	Glossary []ExportGlossary `json:"glossary"`
+	// Only exported if requested, since they can be many.
+	Messages []ExportMessage `json:"messages,omitempty"`
+}
+
+type ExportChannel struct {
+	ID                string               `json:"id"`
+	Language          translator.Language  `json:"language"`
+	AutoDetect        bool                 `json:"auto_detect"`
+	Formality         translator.Formality `json:"formality"`
+	PreserveProfanity bool                 `json:"preserve_profanity"`
+}
+
+type ExportGlossary struct {
+	Term        string              `json:"term"`
+	Language    translator.Language `json:"language"`
+	Replacement *string             `json:"replacement"`
+}
+
+type ExportMessage struct {
+	ChannelID       string              `json:"channel_id"`
+	ID              string              `json:"id"`
+	Language        translator.Language `json:"language"`
+	OriginChannelID *string             `json:"origin_channel_id,omitempty"`
+	OriginID        *string             `json:"origin_id,omitempty"`
+	Provider        *string             `json:"provider,omitempty"`
+	Timestamp       time.Time           `json:"timestamp"`
+}
+
+// Exports a Guild with its Channels, ChannelGroups and GlossaryTerms, and its
+// Messages if messages is true. The data is read in a single transaction.
+//
+// Will return ErrNotFound if the Guild is not found or ErrInternal.
+func ExportGuild[C any](db GuildDB[C], guildID string, messages bool) (GuildExport[C], error) {
+	var e GuildExport[C]
+	err := db.Tx(func(tx GuildDB[C]) error {
+		g, err := tx.Guild(guildID)
+		if err != nil {
+			return err
+		}
+		e = GuildExport[C]{
+			Version:  ExportVersion,
+			GuildID:  g.ID,
+			Config:   g.Config,
+			Channels: []ExportChannel{},
+			Groups:   [][]string{},
+			Glossary: []ExportGlossary{},
+		}
+
+		cs, err := tx.Channels(guildID)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+		for _, c := range cs {
+			e.Channels = append(e.Channels, ExportChannel{
+				c.ID,
+				c.Language,
+				c.AutoDetect,
+				c.Options.Formality,
+				c.Options.PreserveProfanity,
+			})
+		}
+
+		gs, err := tx.ChannelGroups(guildID)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+		for _, g := range gs {
+			ids := make([]string, len(g))
+			for i, c := range g {
+				ids[i] = c.ID
+			}
+			e.Groups = append(e.Groups, ids)
+		}
+
+		ts, err := tx.GlossaryTerms(guildID)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+		for _, t := range ts {
+			e.Glossary = append(e.Glossary, ExportGlossary{t.Term, t.Language, t.Replacement})
+		}
+
+		if !messages {
+			return nil
+		}
+
+		ms, err := tx.Messages(guildID)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+		for _, m := range ms {
+			e.Messages = append(e.Messages, ExportMessage{
+				m.ChannelID,
+				m.ID,
+				m.Language,
+				m.OriginChannelID,
+				m.OriginID,
+				m.Provider,
+				m.Timestamp,
+			})
+		}
+
+		return nil
+	})
+
+	return e, err
+}
+
+// Imports a GuildExport into the Guild of GuildExport.GuildID in a single
+// transaction, so nothing is imported if it fails. Existing objects are updated
+// and Channels in other groups are moved to the exported ones, objects which
+// aren't in the export are kept as is. Channels which aren't in the export stay
+// in their groups, unless moving the exported Channels out leaves them alone in
+// it, in which case the group is deleted.
+//
+// Will return ErrInvalidObject if the version of the export isn't supported or
+// it references Channels which aren't in it, or the errors of GuildDB.
+func ImportGuild[C any](db GuildDB[C], e GuildExport[C]) error {
+	if e.Version < 1 || e.Version > ExportVersion {
+		return errors.Join(
+			ErrInvalidObject,
+			fmt.Errorf(
+				"Export version %d is not supported, latest version is %d",
+				e.Version,
+				ExportVersion,
+			),
+		)
+	} else if e.GuildID == "" {
+		return errors.Join(ErrInvalidObject, errors.New("Export has no guild ID"))
+	}
+
+	return db.Tx(func(tx GuildDB[C]) error {
+		g := NewGuild(e.GuildID, e.Config)
+		if err := tx.GuildInsert(g); errors.Is(err, ErrNoAffect) {
+			if err := tx.GuildUpdate(g); err != nil {
+				return err
+			}
+		} else if err != nil {
+			return err
+		}
+
+		channels := make(map[string]Channel, len(e.Channels))
+		for _, ec := range e.Channels {
+			c := NewChannel(e.GuildID, ec.ID, ec.Language)
+			c.AutoDetect = ec.AutoDetect
+			c.Options = translator.Options{
+				Formality:         ec.Formality,
+				PreserveProfanity: ec.PreserveProfanity,
+			}
+
+			if err := tx.ChannelInsert(c); errors.Is(err, ErrNoAffect) {
+				if err := tx.ChannelUpdate(c); err != nil {
+					return err
+				}
+			} else if err != nil {
+				return err
+			}
+			channels[c.ID] = c
+		}
+
+		for _, ids := range e.Groups {
+			var g ChannelGroup
+			for _, id := range ids {
+				c, ok := channels[id]
+				if !ok {
+					return errors.Join(
+						ErrInvalidObject,
+						fmt.Errorf("Group has channel %s, which is not in the export", id),
+					)
+				}
+
+				err := tx.ChannelGroupRemove(c)
+				if err != nil && !errors.Is(err, ErrNoAffect) {
+					return err
+				}
+				g = append(g, c)
+			}
+
+			if len(g) < 2 {
+				continue
+			}
+			if err := tx.ChannelGroupInsert(g); err != nil {
+				return err
+			}
+		}
+
+		for _, et := range e.Glossary {
+			t := NewGlossaryTerm(e.GuildID, et.Term, et.Language, et.Replacement)
+			if err := tx.GlossaryTermInsert(t); errors.Is(err, ErrNoAffect) {
+				if err := tx.GlossaryTermUpdate(t); err != nil {
+					return err
+				}
+			} else if err != nil {
+				return err
+			}
+		}
+
+		for _, em := range e.Messages {
+			if _, ok := channels[em.ChannelID]; !ok {
+				return errors.Join(
+					ErrInvalidObject,
+					fmt.Errorf(
+						"Message %s is in channel %s, which is not in the export",
+						em.ID,
+						em.ChannelID,
+					),
+				)
+			}
+
+			m := Message{
+				e.GuildID,
+				em.ChannelID,
+				em.ID,
+				em.Language,
+				em.OriginChannelID,
+				em.OriginID,
+				em.Provider,
+				em.Timestamp,
+			}
+			if err := tx.MessageInsert(m); err != nil && !errors.Is(err, ErrNoAffect) {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
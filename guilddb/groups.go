@@ -0,0 +1,143 @@
+package guilddb
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// Queries used by both SQLiteDB and PostgresDB to manage ChannelGroups inside
+// a transaction.
+
+func selectGroupID(tx *sql.Tx, c Channel) (int64, error) {
+	var id int64
+	err := tx.QueryRow(`
+		SELECT GroupID FROM group_members
+			WHERE GuildID = $1 AND ChannelID = $2
+	`, c.GuildID, c.ID).Scan(&id)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, errors.Join(ErrNotFound, fmt.Errorf("Channel %s is not in a group", c.ID))
+	} else if err != nil {
+		return 0, errors.Join(ErrInternal, err)
+	}
+
+	return id, nil
+}
+
+func insertGroupMember(tx *sql.Tx, groupID int64, c Channel) error {
+	r, err := tx.Exec(`
+		INSERT INTO group_members (GuildID, ChannelID, GroupID)
+			VALUES ($1, $2, $3)
+			ON CONFLICT DO NOTHING
+	`, c.GuildID, c.ID, groupID)
+
+	if err != nil {
+		return errors.Join(ErrInternal, err)
+	} else if rows, _ := r.RowsAffected(); rows == 0 {
+		return errors.Join(
+			ErrPreconditionFailed,
+			fmt.Errorf("Channel %s is already in a group", c.ID),
+		)
+	}
+
+	return nil
+}
+
+func mergeGroups(tx *sql.Tx, member, c Channel) error {
+	id, err := selectGroupID(tx, member)
+	if err != nil {
+		return err
+	}
+	old, err := selectGroupID(tx, c)
+	if err != nil {
+		return err
+	} else if id == old {
+		return ErrNoAffect
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE group_members
+			SET GroupID = $1
+			WHERE GroupID = $2
+	`, id, old); err != nil {
+		return errors.Join(ErrInternal, err)
+	}
+
+	if _, err := tx.Exec(`
+		DELETE FROM groups
+			WHERE ID = $1
+	`, old); err != nil {
+		return errors.Join(ErrInternal, err)
+	}
+
+	return nil
+}
+
+func deleteGroup(tx *sql.Tx, id int64) error {
+	if _, err := tx.Exec(`
+		DELETE FROM group_members
+			WHERE GroupID = $1
+	`, id); err != nil {
+		return errors.Join(ErrInternal, err)
+	}
+
+	if _, err := tx.Exec(`
+		DELETE FROM groups
+			WHERE ID = $1
+	`, id); err != nil {
+		return errors.Join(ErrInternal, err)
+	}
+
+	return nil
+}
+
+// Returns the groups of the guild, each one with its channels sorted by ID.
+func selectGroups(q querier, guildID string) ([]ChannelGroup, error) {
+	r, err := q.Query(`
+		SELECT m.GroupID,
+			c.GuildID, c.ID, c.Language, c.AutoDetect, c.Formality, c.PreserveProfanity
+			FROM group_members m
+			JOIN channels c ON c.GuildID = m.GuildID AND c.ID = m.ChannelID
+			WHERE m.GuildID = $1
+			ORDER BY m.GroupID, c.ID
+	`, guildID)
+	if err != nil {
+		return []ChannelGroup{}, errors.Join(ErrInternal, err)
+	}
+	defer r.Close()
+
+	var gs []ChannelGroup
+	var last int64
+	for r.Next() {
+		var id int64
+		var c Channel
+
+		err = r.Scan(
+			&id,
+			&c.GuildID,
+			&c.ID,
+			&c.Language,
+			&c.AutoDetect,
+			&c.Options.Formality,
+			&c.Options.PreserveProfanity,
+		)
+		if err != nil {
+			return gs, errors.Join(ErrInternal, err)
+		}
+
+		if len(gs) == 0 || id != last {
+			gs = append(gs, ChannelGroup{})
+			last = id
+		}
+		gs[len(gs)-1] = append(gs[len(gs)-1], c)
+	}
+	if err := r.Err(); err != nil {
+		return gs, errors.Join(ErrInternal, err)
+	}
+
+	if len(gs) == 0 {
+		return gs, errors.Join(ErrNotFound, fmt.Errorf("Guild %s has no groups", guildID))
+	}
+	return gs, nil
+}
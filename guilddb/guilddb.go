@@ -1,7 +1,9 @@
 package guilddb
 
 import (
+	"encoding/json"
 	"errors"
+	"time"
 
 	"forge.capytal.company/capytal/dislate/translator"
 )
@@ -15,14 +17,40 @@ func NewGuild[C any](ID string, config C) Guild[C] {
 	return Guild[C]{ID, config}
 }
 
+// Configs which implement ConfigValidator are validated before Guilds are
+// stored, so invalid configs are never saved.
+type ConfigValidator interface {
+	Validate() error
+}
+
+// Will return ErrConfigParsing if the config is invalid or can't be encoded.
+func marshalConfig(c any) ([]byte, error) {
+	if v, ok := c.(ConfigValidator); ok {
+		if err := v.Validate(); err != nil {
+			return nil, errors.Join(ErrConfigParsing, err)
+		}
+	}
+
+	j, err := json.Marshal(c)
+	if err != nil {
+		return nil, errors.Join(ErrConfigParsing, err)
+	}
+
+	return j, nil
+}
+
 type Channel struct {
 	GuildID  string
 	ID       string
 	Language translator.Language
+	// Detect the language of each message, using Language only as a fallback.
+	AutoDetect bool
+	// Options used when translating messages to this channel.
+	Options translator.Options
 }
 
 func NewChannel(GuildID, ID string, lang translator.Language) Channel {
-	return Channel{GuildID, ID, lang}
+	return Channel{GuildID, ID, lang, false, translator.Options{}}
 }
 
 type ChannelGroup []Channel
@@ -34,10 +62,16 @@ type Message struct {
 	Language        translator.Language
 	OriginChannelID *string
 	OriginID        *string
+	// Name of the provider which translated the message, nil if the message
+	// isn't a translation or the provider is unknown.
+	Provider *string
+	// When the message was created, used to prune old messages. It is stored
+	// with millisecond precision.
+	Timestamp time.Time
 }
 
 func NewMessage(GuildID, ChannelID, ID string, lang translator.Language) Message {
-	return Message{GuildID, ChannelID, ID, lang, nil, nil}
+	return Message{GuildID, ChannelID, ID, lang, nil, nil, nil, now()}
 }
 
 func NewTranslatedMessage(
@@ -45,7 +79,83 @@ func NewTranslatedMessage(
 	lang translator.Language,
 	OriginChannelID, OriginID string,
 ) Message {
-	return Message{GuildID, ChannelID, ID, lang, &OriginChannelID, &OriginID}
+	return Message{GuildID, ChannelID, ID, lang, &OriginChannelID, &OriginID, nil, now()}
+}
+
+// Returns the current time as it is stored in the database, so messages compare
+// equal before and after being stored.
+func now() time.Time {
+	return time.UnixMilli(time.Now().UnixMilli())
+}
+
+// A user's report of a bad translation. The languages and provider are copied
+// from the messages, so flags are kept even after the messages are deleted.
+type TranslationFlag struct {
+	GuildID         string
+	ChannelID       string
+	MessageID       string
+	UserID          string
+	OriginChannelID string
+	OriginID        string
+	From            translator.Language
+	To              translator.Language
+	Provider        string
+}
+
+// Creates a flag of the translated Message by the user. origin must be the
+// Message which was translated.
+func NewTranslationFlag(m, origin Message, userID string) TranslationFlag {
+	var provider string
+	if m.Provider != nil {
+		provider = *m.Provider
+	}
+	return TranslationFlag{
+		m.GuildID,
+		m.ChannelID,
+		m.ID,
+		userID,
+		origin.ChannelID,
+		origin.ID,
+		origin.Language,
+		m.Language,
+		provider,
+	}
+}
+
+// A translated message and how many users flagged it.
+type FlaggedTranslation struct {
+	GuildID         string
+	ChannelID       string
+	MessageID       string
+	OriginChannelID string
+	OriginID        string
+	From            translator.Language
+	To              translator.Language
+	Provider        string
+	Flags           int
+}
+
+// Filters of FlaggedTranslations, empty fields match any value.
+type FlagFilter struct {
+	// Compared case-insensitively.
+	Provider string
+	From     translator.Language
+	To       translator.Language
+}
+
+type GlossaryTerm struct {
+	GuildID     string
+	Term        string
+	Language    translator.Language
+	Replacement *string
+}
+
+func NewGlossaryTerm(
+	GuildID, term string,
+	lang translator.Language,
+	replacement *string,
+) GlossaryTerm {
+	return GlossaryTerm{GuildID, term, lang, replacement}
 }
 
 type GuildDB[C any] interface {
@@ -88,21 +198,39 @@ type GuildDB[C any] interface {
 	//
 	// Will return ErrNoAffect if no object was deleted or ErrInternal.
 	MessageDelete(m Message) error
-	// Deletes all messages in a Channel in the database. Channel.ID is used to find
-	// the correct messages.
+	// Deletes all messages in a Channel in the database, and their translated
+	// Messages in other Channels. Channel.ID is used to find the correct messages.
 	//
 	// Will return ErrNoAffect if no object was deleted or ErrInternal.
 	MessageDeleteFromChannel(c Channel) error
+	// Deletes up to limit original Messages of a Guild whose Message.Timestamp
+	// is before the provided time, oldest first, together with all of their
+	// translated Messages. Returns the number of original Messages deleted, so
+	// callers can prune in batches until it is less than limit.
+	//
+	// Will return ErrInternal.
+	MessagesPrune(guildID string, before time.Time, limit int) (int, error)
+	// Returns a slice of all Messages of a Guild, original Messages first, so
+	// they can be inserted in order. Both are sorted by Message.Timestamp.
+	//
+	// Will return ErrNotFound if no message is found (slice's length == 0) or ErrInternal.
+	Messages(guildID string) ([]Message, error)
 	// Selects and returns a Channel from the database, based on the
 	// ID provided.
 	//
 	// Will return ErrNotFound if no channel is found or ErrInternal.
 	Channel(guildID, ID string) (Channel, error)
+	// Returns a slice of all Channels of a Guild, sorted by Channel.ID.
+	//
+	// Will return ErrNotFound if no channel is found (slice's length == 0) or ErrInternal.
+	Channels(guildID string) ([]Channel, error)
 	// Inserts a new Channel object in the database.
 	//
-	// Channel.ID must be unique and not already in the database.
+	// Channel.ID must be unique and not already in the database, and
+	// Channel.GuildID must be an already stored Guild object.
 	//
-	// Will return ErrNoAffect if the object already exists or ErrInternal.
+	// Will return ErrPreconditionFailed if the Guild isn't stored, ErrNoAffect if
+	// the object already exists or ErrInternal.
 	ChannelInsert(c Channel) error
 	// Updates the Channel object in the database. Channel.ID is used to find the
 	// correct Channel.
@@ -110,9 +238,12 @@ type GuildDB[C any] interface {
 	// Will return ErrNoAffect if no object was updated or ErrInternal.
 	ChannelUpdate(c Channel) error
 	// Deletes the Channel object in the database. Channel.ID is used to find the
-	// correct Channel.
+	// correct Channel. The Channel must not be in a ChannelGroup or have Messages,
+	// they should be removed with ChannelGroupRemove and MessageDeleteFromChannel
+	// first.
 	//
-	// Will return ErrNoAffect if no object was deleted or ErrInternal.
+	// Will return ErrPreconditionFailed if the Channel is in a ChannelGroup or has
+	// Messages, ErrNoAffect if no object was deleted or ErrInternal.
 	ChannelDelete(c Channel) error
 	// Selects and returns a ChannelGroup from the database. Finds a ChannelGroup
 	// that has a Channel if the provided ID.
@@ -121,36 +252,112 @@ type GuildDB[C any] interface {
 	//
 	// Will return ErrNotFound if no channel is found or ErrInternal.
 	ChannelGroup(guildID, ID string) (ChannelGroup, error)
-	// Inserts a new ChannelGroup object in the database. ChannelGroup must be unique
-	// and not have Channels that are already in other groups.
+	// Returns a slice of all ChannelGroups of a Guild, in the order they were
+	// inserted.
 	//
-	// Will return ErrNoAffect if the object already exists or ErrInternal.
+	// Will return ErrNotFound if no group is found (slice's length == 0) or ErrInternal.
+	ChannelGroups(guildID string) ([]ChannelGroup, error)
+	// Inserts a new ChannelGroup object in the database. ChannelGroup must not
+	// be empty and not have Channels that are already in other groups.
+	//
+	// Will return ErrNoAffect if the group is empty, ErrPreconditionFailed if a
+	// Channel is already in a group or ErrInternal.
 	ChannelGroupInsert(g ChannelGroup) error
-	// Updates the ChannelGroup object in the database.
+	// Adds a Channel to the ChannelGroup which has the member Channel.
 	//
-	// Will return ErrNoAffect if no object was updated or ErrInternal.
-	ChannelGroupUpdate(g ChannelGroup) error
-	// Deletes the ChannelGroup object in the database.
+	// Will return ErrNotFound if member is not in a group, ErrPreconditionFailed
+	// if the Channel is already in a group or ErrInternal.
+	ChannelGroupAdd(member, c Channel) error
+	// Removes a Channel from its ChannelGroup. The group is deleted if less than
+	// two Channels are left in it.
+	//
+	// Will return ErrNoAffect if the Channel is not in a group or ErrInternal.
+	ChannelGroupRemove(c Channel) error
+	// Moves the Channels of the ChannelGroup which has c to the ChannelGroup
+	// which has member, deleting the group of c.
+	//
+	// Will return ErrNotFound if a Channel is not in a group, ErrNoAffect if both
+	// Channels are in the same group or ErrInternal.
+	ChannelGroupMerge(member, c Channel) error
+	// Deletes the ChannelGroups which have the Channels of the group.
 	//
 	// Will return ErrNoAffect if no object was deleted or ErrInternal.
 	ChannelGroupDelete(g ChannelGroup) error
+	// Returns a slice of all GlossaryTerms of a Guild.
+	//
+	// Will return ErrNotFound if no term is found (slice's length == 0) or ErrInternal.
+	GlossaryTerms(guildID string) ([]GlossaryTerm, error)
+	// Inserts a new GlossaryTerm object in the database. GlossaryTerm.Term and
+	// GlossaryTerm.Language must be a unique pair in the Guild. An empty
+	// GlossaryTerm.Language applies the term to all languages, and a nil
+	// GlossaryTerm.Replacement keeps the term as is.
+	//
+	// Will return ErrNoAffect if the object already exists or ErrInternal.
+	GlossaryTermInsert(t GlossaryTerm) error
+	// Updates the GlossaryTerm object in the database. GlossaryTerm.Term and
+	// GlossaryTerm.Language are used to find the correct term.
+	//
+	// Will return ErrNoAffect if no object was updated or ErrInternal.
+	GlossaryTermUpdate(t GlossaryTerm) error
+	// Deletes the GlossaryTerm object in the database. GlossaryTerm.Term and
+	// GlossaryTerm.Language are used to find the correct term.
+	//
+	// Will return ErrNoAffect if no object was deleted or ErrInternal.
+	GlossaryTermDelete(t GlossaryTerm) error
+	// Inserts a new TranslationFlag object in the database. TranslationFlag.MessageID,
+	// TranslationFlag.ChannelID and TranslationFlag.UserID must be unique, so a
+	// user can only flag a message once.
+	//
+	// Will return ErrNoAffect if the object already exists or ErrInternal.
+	TranslationFlagInsert(f TranslationFlag) error
+	// Deletes the TranslationFlag object in the database. TranslationFlag.MessageID,
+	// TranslationFlag.ChannelID and TranslationFlag.UserID are used to find the
+	// correct flag.
+	//
+	// Will return ErrNoAffect if no object was deleted or ErrInternal.
+	TranslationFlagDelete(f TranslationFlag) error
+	// Returns up to limit FlaggedTranslations of a Guild which match the filter,
+	// the most flagged first. The filter is applied before the limit.
+	//
+	// Will return ErrNotFound if no translation was flagged (slice's length == 0)
+	// or ErrInternal.
+	FlaggedTranslations(
+		guildID string,
+		filter FlagFilter,
+		limit int,
+	) ([]FlaggedTranslation, error)
 	// Selects and returns a Guild from the database.
 	//
-	// Will return ErrNotFound if no Guild is found or ErrInternal.
+	// Will return ErrNotFound if no Guild is found, ErrConfigParsing if its config
+	// can't be decoded or ErrInternal.
 	Guild(ID string) (Guild[C], error)
 	// Inserts a new Guild object in the database. Guild.ID must be unique and
 	// not already in the database.
 	//
-	// Will return ErrNoAffect if the object already exists or ErrInternal.
+	// Will return ErrConfigParsing if Guild.Config is invalid, ErrNoAffect if the
+	// object already exists or ErrInternal.
 	GuildInsert(g Guild[C]) error
-	// Delete a Guild from the database. Guild.ID is used to find the object.
+	// Delete a Guild from the database. Guild.ID is used to find the object. The
+	// Guild must not have Channels, ChannelGroups, GlossaryTerms or
+	// TranslationFlags, they should be deleted first.
 	//
-	// Will return ErrNoAffect if no object was deleted or ErrInternal.
+	// Will return ErrPreconditionFailed if the Guild still has any of them,
+	// ErrNoAffect if no object was deleted or ErrInternal.
 	GuildDelete(g Guild[C]) error
 	// Updates the Guild object in the database.
 	//
-	// Will return ErrNoAffect if no object was updated or ErrInternal.
+	// Will return ErrConfigParsing if Guild.Config is invalid, ErrNoAffect if no
+	// object was updated or ErrInternal.
 	GuildUpdate(g Guild[C]) error
+	// Runs f in a transaction, with tx scoped to it. Changes made with tx are
+	// committed if f returns nil and rolled back otherwise. Calling Tx on tx
+	// nests the transaction, only rolling back the changes of the nested f.
+	//
+	// The database which Tx was called on must not be used inside f, as it may
+	// wait for the transaction to end, and tx must not be used after f returns.
+	//
+	// Will return the error returned by f or ErrInternal.
+	Tx(f func(tx GuildDB[C]) error) error
 }
 
 var (
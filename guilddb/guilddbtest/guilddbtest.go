@@ -0,0 +1,860 @@
+// Package guilddbtest implements a conformance suite for guilddb.GuildDB
+// implementations, checking that they follow the behavior and errors
+// documented by the interface.
+package guilddbtest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"forge.capytal.company/capytal/dislate/guilddb"
+	"forge.capytal.company/capytal/dislate/translator"
+)
+
+// Config stored in the guilds of the suite. It has characters which need to be
+// escaped to check that it is stored as is.
+type Config struct {
+	Name string `json:"name"`
+}
+
+// Configs with names longer than 100 bytes are invalid, to check that configs
+// are validated before being stored.
+func (c Config) Validate() error {
+	if len(c.Name) > 100 {
+		return errors.New("Name is too long")
+	}
+	return nil
+}
+
+// Returns a new empty and prepared database, closing it with t.Cleanup.
+type Factory func(t *testing.T) guilddb.GuildDB[Config]
+
+// Factory of SQLiteDBs stored in a temporary file.
+func SQLite(t *testing.T) guilddb.GuildDB[Config] {
+	t.Helper()
+
+	db, err := guilddb.NewSQLiteDB[Config]("file:" + filepath.Join(t.TempDir(), "guild.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %s", err)
+	}
+	t.Cleanup(func() {
+		if err := db.Close(); err != nil {
+			t.Errorf("Failed to close database: %s", err)
+		}
+	})
+
+	if err := db.Prepare(); err != nil {
+		t.Fatalf("Failed to prepare database: %s", err)
+	}
+
+	return db
+}
+
+// Factory of PostgresDBs, each one in a new server started with initdb and
+// pg_ctl in a temporary directory. Skips the test if they aren't installed.
+func Postgres(t *testing.T) guilddb.GuildDB[Config] {
+	t.Helper()
+
+	initdb, err := exec.LookPath("initdb")
+	if err != nil {
+		t.Skip("initdb not found, skipping PostgreSQL tests")
+	}
+	pgctl, err := exec.LookPath("pg_ctl")
+	if err != nil {
+		t.Skip("pg_ctl not found, skipping PostgreSQL tests")
+	}
+
+	data := filepath.Join(t.TempDir(), "data")
+	if out, err := exec.Command(
+		initdb, "-D", data, "-U", "postgres", "-A", "trust", "-E", "UTF8",
+	).CombinedOutput(); err != nil {
+		t.Fatalf("Failed to initialize database cluster: %s\n%s", err, out)
+	}
+
+	// Unix socket paths are limited to around 100 bytes, which the paths of
+	// t.TempDir can exceed, so the server listens in a shorter directory.
+	socket, err := os.MkdirTemp("", "guilddb")
+	if err != nil {
+		t.Fatalf("Failed to create socket directory: %s", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(socket) })
+
+	if out, err := exec.Command(
+		pgctl, "-D", data, "-l", filepath.Join(data, "log"), "-w",
+		"-o", fmt.Sprintf("-k %s -c listen_addresses=''", socket),
+		"start",
+	).CombinedOutput(); err != nil {
+		t.Fatalf("Failed to start database server: %s\n%s", err, out)
+	}
+	t.Cleanup(func() {
+		if out, err := exec.Command(
+			pgctl, "-D", data, "-w", "-m", "fast", "stop",
+		).CombinedOutput(); err != nil {
+			t.Errorf("Failed to stop database server: %s\n%s", err, out)
+		}
+	})
+
+	db, err := guilddb.NewPostgresDB[Config]("postgres://postgres@/postgres?host=" + socket)
+	if err != nil {
+		t.Fatalf("Failed to open database: %s", err)
+	}
+	t.Cleanup(func() {
+		if err := db.Close(); err != nil {
+			t.Errorf("Failed to close database: %s", err)
+		}
+	})
+
+	if err := db.Prepare(); err != nil {
+		t.Fatalf("Failed to prepare database: %s", err)
+	}
+
+	return db
+}
+
+// Factory of MemoryDBs.
+func Memory(t *testing.T) guilddb.GuildDB[Config] {
+	return guilddb.NewMemoryDB[Config]()
+}
+
+// Runs all the conformance tests, each one with a new database. Implementations
+// are tested with a single line, for example Run(t, guilddbtest.SQLite).
+func Run(t *testing.T, newDB Factory) {
+	t.Run("Guilds", func(t *testing.T) { testGuilds(t, newDB(t)) })
+	t.Run("Channels", func(t *testing.T) { testChannels(t, newDB(t)) })
+	t.Run("Messages", func(t *testing.T) { testMessages(t, newDB(t)) })
+	t.Run("MessagesPrune", func(t *testing.T) { testMessagesPrune(t, newDB(t)) })
+	t.Run("ChannelGroups", func(t *testing.T) { testChannelGroups(t, newDB(t)) })
+	t.Run("Glossary", func(t *testing.T) { testGlossary(t, newDB(t)) })
+	t.Run("TranslationFlags", func(t *testing.T) { testTranslationFlags(t, newDB(t)) })
+	t.Run("Tx", func(t *testing.T) { testTx(t, newDB(t)) })
+	t.Run("Export", func(t *testing.T) { testExport(t, newDB(t), newDB(t)) })
+	t.Run("Import", func(t *testing.T) { testImport(t, newDB(t)) })
+	t.Run("Concurrency", func(t *testing.T) { testConcurrency(t, newDB(t)) })
+}
+
+const guildID = "100"
+
+func testGuilds(t *testing.T, db guilddb.GuildDB[Config]) {
+	g := guilddb.NewGuild(guildID, Config{`It's a "guild"`})
+
+	_, err := db.Guild(g.ID)
+	is(t, err, guilddb.ErrNotFound)
+
+	is(t, db.GuildInsert(g), nil)
+	is(t, db.GuildInsert(g), guilddb.ErrNoAffect)
+
+	r, err := db.Guild(g.ID)
+	is(t, err, nil)
+	equal(t, r, g)
+
+	g.Config.Name = "Renamed"
+	is(t, db.GuildUpdate(g), nil)
+	r, err = db.Guild(g.ID)
+	is(t, err, nil)
+	equal(t, r, g)
+
+	is(t, db.GuildUpdate(guilddb.NewGuild("404", Config{})), guilddb.ErrNoAffect)
+
+	invalid := guilddb.NewGuild(g.ID, Config{strings.Repeat("a", 101)})
+	is(t, db.GuildUpdate(invalid), guilddb.ErrConfigParsing)
+	invalid.ID = "101"
+	is(t, db.GuildInsert(invalid), guilddb.ErrConfigParsing)
+	_, err = db.Guild(invalid.ID)
+	is(t, err, guilddb.ErrNotFound)
+	r, err = db.Guild(g.ID)
+	is(t, err, nil)
+	equal(t, r, g)
+
+	// Guilds with channels, glossary terms or flags can't be deleted, so none
+	// of them is left without its guild
+	c1 := insertChannel(t, db, "200", translator.EN)
+	c2 := insertChannel(t, db, "201", translator.PT)
+	is(t, db.ChannelGroupInsert(guilddb.ChannelGroup{c1, c2}), nil)
+	is(t, db.GuildDelete(g), guilddb.ErrPreconditionFailed)
+	is(t, db.ChannelGroupRemove(c1), nil)
+	is(t, db.ChannelDelete(c1), nil)
+	is(t, db.GuildDelete(g), guilddb.ErrPreconditionFailed)
+	is(t, db.ChannelDelete(c2), nil)
+
+	term := guilddb.NewGlossaryTerm(g.ID, "Capytal", translator.EN, nil)
+	is(t, db.GlossaryTermInsert(term), nil)
+	is(t, db.GuildDelete(g), guilddb.ErrPreconditionFailed)
+	is(t, db.GlossaryTermDelete(term), nil)
+
+	origin := guilddb.NewMessage(g.ID, "200", "300", translator.EN)
+	m := guilddb.NewTranslatedMessage(g.ID, "201", "301", translator.PT, "200", "300")
+	flag := guilddb.NewTranslationFlag(m, origin, "400")
+	is(t, db.TranslationFlagInsert(flag), nil)
+	is(t, db.GuildDelete(g), guilddb.ErrPreconditionFailed)
+	is(t, db.TranslationFlagDelete(flag), nil)
+
+	r, err = db.Guild(g.ID)
+	is(t, err, nil)
+	equal(t, r, g)
+
+	is(t, db.GuildDelete(g), nil)
+	is(t, db.GuildDelete(g), guilddb.ErrNoAffect)
+	_, err = db.Guild(g.ID)
+	is(t, err, guilddb.ErrNotFound)
+}
+
+func testChannels(t *testing.T, db guilddb.GuildDB[Config]) {
+	c := guilddb.NewChannel(guildID, "200", translator.EN)
+
+	// Channels must be of a stored guild
+	is(t, db.ChannelInsert(c), guilddb.ErrPreconditionFailed)
+	insertGuild(t, db)
+
+	_, err := db.Channel(c.GuildID, c.ID)
+	is(t, err, guilddb.ErrNotFound)
+
+	is(t, db.ChannelInsert(c), nil)
+	is(t, db.ChannelInsert(c), guilddb.ErrNoAffect)
+
+	r, err := db.Channel(c.GuildID, c.ID)
+	is(t, err, nil)
+	equal(t, r, c)
+
+	c.Language = translator.PT
+	c.AutoDetect = true
+	c.Options = translator.Options{
+		Formality:         translator.FormalityInformal,
+		PreserveProfanity: true,
+	}
+	is(t, db.ChannelUpdate(c), nil)
+	r, err = db.Channel(c.GuildID, c.ID)
+	is(t, err, nil)
+	equal(t, r, c)
+
+	is(t, db.ChannelUpdate(guilddb.NewChannel(guildID, "404", translator.EN)), guilddb.ErrNoAffect)
+
+	// Channels in groups or with messages can't be deleted, so no group member
+	// or message is left without its channel
+	c2 := insertChannel(t, db, "201", translator.PT)
+	is(t, db.ChannelGroupInsert(guilddb.ChannelGroup{c, c2}), nil)
+	is(t, db.ChannelDelete(c), guilddb.ErrPreconditionFailed)
+	g, err := db.ChannelGroup(guildID, c2.ID)
+	is(t, err, nil)
+	equal(t, g, guilddb.ChannelGroup{c, c2})
+	is(t, db.ChannelGroupRemove(c), nil)
+
+	is(t, db.MessageInsert(guilddb.NewMessage(guildID, c.ID, "300", c.Language)), nil)
+	is(t, db.ChannelDelete(c), guilddb.ErrPreconditionFailed)
+	r, err = db.Channel(c.GuildID, c.ID)
+	is(t, err, nil)
+	equal(t, r, c)
+	is(t, db.MessageDeleteFromChannel(c), nil)
+
+	is(t, db.ChannelDelete(c), nil)
+	is(t, db.ChannelDelete(c), guilddb.ErrNoAffect)
+	_, err = db.Channel(c.GuildID, c.ID)
+	is(t, err, guilddb.ErrNotFound)
+}
+
+func testMessages(t *testing.T, db guilddb.GuildDB[Config]) {
+	insertGuild(t, db)
+	c1 := insertChannel(t, db, "200", translator.EN)
+	c2 := insertChannel(t, db, "201", translator.PT)
+
+	is(
+		t,
+		db.MessageInsert(guilddb.NewMessage(guildID, "404", "300", translator.EN)),
+		guilddb.ErrPreconditionFailed,
+	)
+
+	m1 := guilddb.NewMessage(guildID, c1.ID, "300", translator.EN)
+	m2 := guilddb.NewTranslatedMessage(guildID, c2.ID, "301", translator.PT, m1.ChannelID, m1.ID)
+	provider := "mock"
+	m2.Provider = &provider
+
+	_, err := db.Message(guildID, m1.ChannelID, m1.ID)
+	is(t, err, guilddb.ErrNotFound)
+
+	is(t, db.MessageInsert(m1), nil)
+	_, err = db.MessagesWithOrigin(guildID, m1.ChannelID, m1.ID)
+	is(t, err, guilddb.ErrNotFound)
+
+	is(t, db.MessageInsert(m2), nil)
+	is(t, db.MessageInsert(m1), guilddb.ErrNoAffect)
+
+	r, err := db.Message(guildID, m2.ChannelID, m2.ID)
+	is(t, err, nil)
+	equal(t, r, m2)
+
+	rs, err := db.MessagesWithOrigin(guildID, m1.ChannelID, m1.ID)
+	is(t, err, nil)
+	equal(t, rs, []guilddb.Message{m2})
+
+	r, err = db.MessageWithOriginByLang(guildID, m1.ChannelID, m1.ID, translator.PT)
+	is(t, err, nil)
+	equal(t, r, m2)
+	_, err = db.MessageWithOriginByLang(guildID, m1.ChannelID, m1.ID, translator.ES)
+	is(t, err, guilddb.ErrNotFound)
+
+	provider = "other"
+	is(t, db.MessageUpdate(m2), nil)
+	r, err = db.Message(guildID, m2.ChannelID, m2.ID)
+	is(t, err, nil)
+	equal(t, r, m2)
+
+	is(
+		t,
+		db.MessageUpdate(guilddb.NewMessage(guildID, c1.ID, "404", translator.EN)),
+		guilddb.ErrNoAffect,
+	)
+
+	// Deleting a message also deletes its translations
+	is(t, db.MessageDelete(m1), nil)
+	is(t, db.MessageDelete(m1), guilddb.ErrNoAffect)
+	_, err = db.Message(guildID, m2.ChannelID, m2.ID)
+	is(t, err, guilddb.ErrNotFound)
+
+	m3 := guilddb.NewMessage(guildID, c2.ID, "302", translator.PT)
+	is(t, db.MessageInsert(m3), nil)
+	is(t, db.MessageDeleteFromChannel(c2), nil)
+	is(t, db.MessageDeleteFromChannel(c2), guilddb.ErrNoAffect)
+	_, err = db.Message(guildID, m3.ChannelID, m3.ID)
+	is(t, err, guilddb.ErrNotFound)
+
+	// Deleting the messages of a channel also deletes their translations
+	m4 := guilddb.NewMessage(guildID, c1.ID, "303", translator.EN)
+	m5 := guilddb.NewTranslatedMessage(guildID, c2.ID, "304", translator.PT, m4.ChannelID, m4.ID)
+	is(t, db.MessageInsert(m4), nil)
+	is(t, db.MessageInsert(m5), nil)
+	is(t, db.MessageDeleteFromChannel(c1), nil)
+	_, err = db.Message(guildID, m5.ChannelID, m5.ID)
+	is(t, err, guilddb.ErrNotFound)
+}
+
+func testMessagesPrune(t *testing.T, db guilddb.GuildDB[Config]) {
+	insertGuild(t, db)
+	c1 := insertChannel(t, db, "200", translator.EN)
+	c2 := insertChannel(t, db, "201", translator.PT)
+
+	now := time.Now()
+	var old []guilddb.Message
+	for i := range 3 {
+		m := guilddb.NewMessage(guildID, c1.ID, strconv.Itoa(300+i), translator.EN)
+		m.Timestamp = now.Add(-time.Duration(3-i) * time.Hour)
+		tm := guilddb.NewTranslatedMessage(
+			guildID, c2.ID, strconv.Itoa(400+i), translator.PT, m.ChannelID, m.ID,
+		)
+		tm.Timestamp = m.Timestamp
+		is(t, db.MessageInsert(m), nil)
+		is(t, db.MessageInsert(tm), nil)
+		old = append(old, m)
+	}
+	recent := guilddb.NewMessage(guildID, c1.ID, "500", translator.EN)
+	is(t, db.MessageInsert(recent), nil)
+
+	n, err := db.MessagesPrune(guildID, now.Add(-time.Minute), 2)
+	is(t, err, nil)
+	equal(t, n, 2)
+
+	// Oldest messages are pruned first, together with their translations
+	for _, m := range old[:2] {
+		_, err = db.Message(guildID, m.ChannelID, m.ID)
+		is(t, err, guilddb.ErrNotFound)
+		_, err = db.MessagesWithOrigin(guildID, m.ChannelID, m.ID)
+		is(t, err, guilddb.ErrNotFound)
+	}
+	_, err = db.Message(guildID, old[2].ChannelID, old[2].ID)
+	is(t, err, nil)
+
+	n, err = db.MessagesPrune(guildID, now.Add(-time.Minute), 2)
+	is(t, err, nil)
+	equal(t, n, 1)
+
+	n, err = db.MessagesPrune("404", now, 2)
+	is(t, err, nil)
+	equal(t, n, 0)
+
+	n, err = db.MessagesPrune(guildID, now.Add(-time.Minute), 2)
+	is(t, err, nil)
+	equal(t, n, 0)
+	r, err := db.Message(guildID, recent.ChannelID, recent.ID)
+	is(t, err, nil)
+	equal(t, r, recent)
+}
+
+func testChannelGroups(t *testing.T, db guilddb.GuildDB[Config]) {
+	insertGuild(t, db)
+	c1 := insertChannel(t, db, "200", translator.EN)
+	c2 := insertChannel(t, db, "201", translator.PT)
+	c3 := insertChannel(t, db, "202", translator.ES)
+	c4 := insertChannel(t, db, "203", translator.FR)
+	c5 := insertChannel(t, db, "204", translator.DE)
+
+	_, err := db.ChannelGroup(guildID, c1.ID)
+	is(t, err, guilddb.ErrNotFound)
+	is(t, db.ChannelGroupRemove(c1), guilddb.ErrNoAffect)
+	is(t, db.ChannelGroupDelete(guilddb.ChannelGroup{c1}), guilddb.ErrNoAffect)
+
+	is(t, db.ChannelGroupInsert(guilddb.ChannelGroup{}), guilddb.ErrNoAffect)
+	is(t, db.ChannelGroupInsert(guilddb.ChannelGroup{c1, c2}), nil)
+
+	g, err := db.ChannelGroup(guildID, c2.ID)
+	is(t, err, nil)
+	equal(t, g, guilddb.ChannelGroup{c1, c2})
+
+	// Channels can only be in one group, and failed inserts change nothing
+	is(t, db.ChannelGroupInsert(guilddb.ChannelGroup{c3, c1}), guilddb.ErrPreconditionFailed)
+	_, err = db.ChannelGroup(guildID, c3.ID)
+	is(t, err, guilddb.ErrNotFound)
+
+	is(t, db.ChannelGroupAdd(c1, c3), nil)
+	is(t, db.ChannelGroupAdd(c2, c3), guilddb.ErrPreconditionFailed)
+	is(t, db.ChannelGroupAdd(c4, c5), guilddb.ErrNotFound)
+
+	g, err = db.ChannelGroup(guildID, c3.ID)
+	is(t, err, nil)
+	equal(t, g, guilddb.ChannelGroup{c1, c2, c3})
+
+	is(t, db.ChannelGroupInsert(guilddb.ChannelGroup{c4, c5}), nil)
+
+	is(t, db.ChannelGroupRemove(c2), nil)
+	is(t, db.ChannelGroupRemove(c2), guilddb.ErrNoAffect)
+	g, err = db.ChannelGroup(guildID, c1.ID)
+	is(t, err, nil)
+	equal(t, g, guilddb.ChannelGroup{c1, c3})
+
+	// Groups with a single channel are deleted
+	is(t, db.ChannelGroupRemove(c3), nil)
+	_, err = db.ChannelGroup(guildID, c1.ID)
+	is(t, err, guilddb.ErrNotFound)
+
+	// Merging moves all channels to the group of the first one
+	is(t, db.ChannelGroupInsert(guilddb.ChannelGroup{c1, c2}), nil)
+	is(t, db.ChannelGroupMerge(c1, c3), guilddb.ErrNotFound)
+	is(t, db.ChannelGroupMerge(c1, c2), guilddb.ErrNoAffect)
+	is(t, db.ChannelGroupMerge(c4, c1), nil)
+	g, err = db.ChannelGroup(guildID, c2.ID)
+	is(t, err, nil)
+	equal(t, g, guilddb.ChannelGroup{c1, c2, c4, c5})
+	gs, err := db.ChannelGroups(guildID)
+	is(t, err, nil)
+	equal(t, len(gs), 1)
+
+	is(t, db.ChannelGroupDelete(guilddb.ChannelGroup{c5}), nil)
+	is(t, db.ChannelGroupDelete(guilddb.ChannelGroup{c5}), guilddb.ErrNoAffect)
+	_, err = db.ChannelGroup(guildID, c4.ID)
+	is(t, err, guilddb.ErrNotFound)
+}
+
+func testGlossary(t *testing.T, db guilddb.GuildDB[Config]) {
+	insertGuild(t, db)
+
+	_, err := db.GlossaryTerms(guildID)
+	is(t, err, guilddb.ErrNotFound)
+
+	replacement := "Capytal Company"
+	t1 := guilddb.NewGlossaryTerm(guildID, "Capytal", translator.EN, nil)
+	t2 := guilddb.NewGlossaryTerm(guildID, "Capytal", "", &replacement)
+
+	is(t, db.GlossaryTermInsert(t1), nil)
+	is(t, db.GlossaryTermInsert(t2), nil)
+
+	// Terms are case-insensitive
+	is(
+		t,
+		db.GlossaryTermInsert(guilddb.NewGlossaryTerm(guildID, "capytal", translator.EN, nil)),
+		guilddb.ErrNoAffect,
+	)
+
+	ts, err := db.GlossaryTerms(guildID)
+	is(t, err, nil)
+	equal(t, ts, []guilddb.GlossaryTerm{t2, t1})
+
+	t1.Replacement = &replacement
+	is(t, db.GlossaryTermUpdate(t1), nil)
+	ts, err = db.GlossaryTerms(guildID)
+	is(t, err, nil)
+	equal(t, ts, []guilddb.GlossaryTerm{t2, t1})
+
+	is(
+		t,
+		db.GlossaryTermUpdate(guilddb.NewGlossaryTerm(guildID, "404", translator.EN, nil)),
+		guilddb.ErrNoAffect,
+	)
+
+	is(t, db.GlossaryTermDelete(t1), nil)
+	is(t, db.GlossaryTermDelete(t1), guilddb.ErrNoAffect)
+	is(t, db.GlossaryTermDelete(t2), nil)
+	_, err = db.GlossaryTerms(guildID)
+	is(t, err, guilddb.ErrNotFound)
+}
+
+func testTranslationFlags(t *testing.T, db guilddb.GuildDB[Config]) {
+	insertGuild(t, db)
+
+	_, err := db.FlaggedTranslations(guildID, guilddb.FlagFilter{}, 10)
+	is(t, err, guilddb.ErrNotFound)
+
+	provider := "mock"
+	origin := guilddb.NewMessage(guildID, "200", "300", translator.EN)
+	m1 := guilddb.NewTranslatedMessage(guildID, "201", "301", translator.PT, "200", "300")
+	m1.Provider = &provider
+	m2 := guilddb.NewTranslatedMessage(guildID, "202", "302", translator.ES, "200", "300")
+	m2.Provider = &provider
+
+	f1 := guilddb.NewTranslationFlag(m1, origin, "400")
+	f2 := guilddb.NewTranslationFlag(m1, origin, "401")
+	f3 := guilddb.NewTranslationFlag(m2, origin, "400")
+
+	is(t, db.TranslationFlagInsert(f1), nil)
+	is(t, db.TranslationFlagInsert(f2), nil)
+	is(t, db.TranslationFlagInsert(f3), nil)
+	is(t, db.TranslationFlagInsert(f1), guilddb.ErrNoAffect)
+
+	fs, err := db.FlaggedTranslations(guildID, guilddb.FlagFilter{}, 10)
+	is(t, err, nil)
+	equal(t, fs, []guilddb.FlaggedTranslation{
+		flagged(f1, 2),
+		flagged(f3, 1),
+	})
+
+	fs, err = db.FlaggedTranslations(guildID, guilddb.FlagFilter{}, 1)
+	is(t, err, nil)
+	equal(t, fs, []guilddb.FlaggedTranslation{flagged(f1, 2)})
+
+	// Filters are applied before the limit
+	fs, err = db.FlaggedTranslations(guildID, guilddb.FlagFilter{To: translator.ES}, 1)
+	is(t, err, nil)
+	equal(t, fs, []guilddb.FlaggedTranslation{flagged(f3, 1)})
+	fs, err = db.FlaggedTranslations(
+		guildID,
+		guilddb.FlagFilter{Provider: "MOCK", From: translator.EN, To: translator.PT},
+		10,
+	)
+	is(t, err, nil)
+	equal(t, fs, []guilddb.FlaggedTranslation{flagged(f1, 2)})
+	_, err = db.FlaggedTranslations(guildID, guilddb.FlagFilter{Provider: "other"}, 10)
+	is(t, err, guilddb.ErrNotFound)
+
+	is(t, db.TranslationFlagDelete(f2), nil)
+	is(t, db.TranslationFlagDelete(f2), guilddb.ErrNoAffect)
+	is(t, db.TranslationFlagDelete(f1), nil)
+	is(t, db.TranslationFlagDelete(f3), nil)
+	_, err = db.FlaggedTranslations(guildID, guilddb.FlagFilter{}, 10)
+	is(t, err, guilddb.ErrNotFound)
+}
+
+// Checks that changes made in Tx are committed when it succeeds and rolled
+// back when it fails, including the changes of nested transactions.
+func testTx(t *testing.T, db guilddb.GuildDB[Config]) {
+	insertGuild(t, db)
+	c1 := guilddb.NewChannel(guildID, "200", translator.EN)
+	c2 := guilddb.NewChannel(guildID, "201", translator.PT)
+	c3 := guilddb.NewChannel(guildID, "202", translator.ES)
+
+	errTest := errors.New("Test error")
+
+	is(t, db.Tx(func(tx guilddb.GuildDB[Config]) error {
+		is(t, tx.ChannelInsert(c1), nil)
+		return errTest
+	}), errTest)
+	_, err := db.Channel(c1.GuildID, c1.ID)
+	is(t, err, guilddb.ErrNotFound)
+
+	is(t, db.Tx(func(tx guilddb.GuildDB[Config]) error {
+		is(t, tx.ChannelInsert(c1), nil)
+		is(t, tx.ChannelInsert(c2), nil)
+
+		is(t, tx.Tx(func(tx guilddb.GuildDB[Config]) error {
+			is(t, tx.ChannelInsert(c3), nil)
+			return errTest
+		}), errTest)
+		_, err := tx.Channel(c3.GuildID, c3.ID)
+		is(t, err, guilddb.ErrNotFound)
+
+		is(t, tx.ChannelGroupInsert(guilddb.ChannelGroup{c1, c2}), nil)
+		return nil
+	}), nil)
+
+	g, err := db.ChannelGroup(c1.GuildID, c1.ID)
+	is(t, err, nil)
+	equal(t, g, guilddb.ChannelGroup{c1, c2})
+	_, err = db.Channel(c3.GuildID, c3.ID)
+	is(t, err, guilddb.ErrNotFound)
+
+	// Failed group changes must not leave partial groups in the transaction
+	is(t, db.Tx(func(tx guilddb.GuildDB[Config]) error {
+		is(t, tx.ChannelInsert(c3), nil)
+		is(t, tx.ChannelGroupInsert(guilddb.ChannelGroup{c3, c1}), guilddb.ErrPreconditionFailed)
+		return nil
+	}), nil)
+	_, err = db.ChannelGroup(c3.GuildID, c3.ID)
+	is(t, err, guilddb.ErrNotFound)
+}
+
+func testExport(t *testing.T, db, dest guilddb.GuildDB[Config]) {
+	is(t, db.GuildInsert(guilddb.NewGuild(guildID, Config{`It's a "guild"`})), nil)
+	c1 := insertChannel(t, db, "200", translator.EN)
+	c2 := insertChannel(t, db, "201", translator.PT)
+	c3 := insertChannel(t, db, "202", translator.ES)
+	c4 := insertChannel(t, db, "203", translator.FR)
+	c2.AutoDetect = true
+	c2.Options.Formality = translator.FormalityFormal
+	is(t, db.ChannelUpdate(c2), nil)
+	is(t, db.ChannelGroupInsert(guilddb.ChannelGroup{c1, c2}), nil)
+	is(t, db.ChannelGroupInsert(guilddb.ChannelGroup{c3, c4}), nil)
+
+	replacement := "Dislate"
+	is(t, db.GlossaryTermInsert(guilddb.NewGlossaryTerm(guildID, "dislate", "", &replacement)), nil)
+
+	m := guilddb.NewMessage(guildID, c1.ID, "300", translator.EN)
+	tm := guilddb.NewTranslatedMessage(guildID, c2.ID, "301", translator.PT, m.ChannelID, m.ID)
+	is(t, db.MessageInsert(m), nil)
+	is(t, db.MessageInsert(tm), nil)
+
+	_, err := guilddb.ExportGuild(db, "404", false)
+	is(t, err, guilddb.ErrNotFound)
+
+	e, err := guilddb.ExportGuild(db, guildID, false)
+	is(t, err, nil)
+	equal(t, e.Version, guilddb.ExportVersion)
+	equal(t, e.Groups, [][]string{{c1.ID, c2.ID}, {c3.ID, c4.ID}})
+	equal(t, len(e.Channels), 4)
+	equal(t, len(e.Glossary), 1)
+	equal(t, len(e.Messages), 0)
+
+	e, err = guilddb.ExportGuild(db, guildID, true)
+	is(t, err, nil)
+	equal(t, len(e.Messages), 2)
+
+	// Exports are imported from their JSON documents
+	b, err := json.Marshal(e)
+	is(t, err, nil)
+	var r guilddb.GuildExport[Config]
+	is(t, json.Unmarshal(b, &r), nil)
+
+	// Channels of the destination are moved to the exported groups
+	insertGuild(t, dest)
+	d1 := insertChannel(t, dest, c1.ID, translator.EN)
+	d5 := insertChannel(t, dest, "204", translator.EN)
+	is(t, dest.ChannelGroupInsert(guilddb.ChannelGroup{d1, d5}), nil)
+
+	is(t, guilddb.ImportGuild(dest, r), nil)
+	is(t, guilddb.ImportGuild(dest, r), nil)
+
+	re, err := guilddb.ExportGuild(dest, guildID, true)
+	is(t, err, nil)
+	equal(t, re.Config, e.Config)
+	equal(t, re.Groups, e.Groups)
+	equal(t, re.Glossary, e.Glossary)
+	equal(t, len(re.Channels), 5)
+	equal(t, len(re.Messages), 2)
+
+	ch, err := dest.Channel(guildID, c2.ID)
+	is(t, err, nil)
+	equal(t, ch, c2)
+	msg, err := dest.Message(guildID, tm.ChannelID, tm.ID)
+	is(t, err, nil)
+	equal(t, msg, tm)
+
+	// Invalid exports don't change the database
+	r.Version = guilddb.ExportVersion + 1
+	is(t, guilddb.ImportGuild(dest, r), guilddb.ErrInvalidObject)
+
+	r.Version = guilddb.ExportVersion
+	r.Config.Name = "Changed"
+	r.Groups = append(r.Groups, []string{c1.ID, "404"})
+	is(t, guilddb.ImportGuild(dest, r), guilddb.ErrInvalidObject)
+	g, err := dest.Guild(guildID)
+	is(t, err, nil)
+	equal(t, g.Config, e.Config)
+}
+
+func testImport(t *testing.T, db guilddb.GuildDB[Config]) {
+	insertGuild(t, db)
+	c1 := insertChannel(t, db, "200", translator.EN)
+	c2 := insertChannel(t, db, "201", translator.EN)
+	c3 := insertChannel(t, db, "202", translator.EN)
+	c5 := insertChannel(t, db, "204", translator.DE)
+	c6 := insertChannel(t, db, "205", translator.DE)
+	c7 := insertChannel(t, db, "206", translator.FR)
+	c8 := insertChannel(t, db, "207", translator.ES)
+	is(t, db.ChannelGroupInsert(guilddb.ChannelGroup{c1, c2, c5}), nil)
+	is(t, db.ChannelGroupInsert(guilddb.ChannelGroup{c3, c6}), nil)
+	is(t, db.ChannelGroupInsert(guilddb.ChannelGroup{c7, c8}), nil)
+
+	valid := func() guilddb.GuildExport[Config] {
+		return guilddb.GuildExport[Config]{
+			Version: guilddb.ExportVersion,
+			GuildID: guildID,
+			Config:  Config{"Imported"},
+			Channels: []guilddb.ExportChannel{
+				{ID: "200", Language: translator.PT},
+				{ID: "201", Language: translator.EN},
+				{ID: "202", Language: translator.ES},
+				{ID: "203", Language: translator.FR},
+			},
+			Groups: [][]string{{"200", "201"}, {"202", "203"}},
+			Messages: []guilddb.ExportMessage{
+				{ChannelID: "200", ID: "300", Language: translator.PT},
+			},
+		}
+	}
+
+	// Invalid exports fail without changing the database
+	invalid := []func(e *guilddb.GuildExport[Config]){
+		func(e *guilddb.GuildExport[Config]) { e.Version = 0 },
+		func(e *guilddb.GuildExport[Config]) { e.Version = guilddb.ExportVersion + 1 },
+		func(e *guilddb.GuildExport[Config]) { e.GuildID = "" },
+		func(e *guilddb.GuildExport[Config]) { e.Groups[1] = []string{"202", "404"} },
+		func(e *guilddb.GuildExport[Config]) { e.Messages[0].ChannelID = "404" },
+	}
+	for _, f := range invalid {
+		e := valid()
+		f(&e)
+		is(t, guilddb.ImportGuild(db, e), guilddb.ErrInvalidObject)
+
+		g, err := db.Guild(guildID)
+		is(t, err, nil)
+		equal(t, g.Config, Config{"Guild"})
+		_, err = db.Channel(guildID, "203")
+		is(t, err, guilddb.ErrNotFound)
+		_, err = db.Message(guildID, "200", "300")
+		is(t, err, guilddb.ErrNotFound)
+		gs, err := db.ChannelGroups(guildID)
+		is(t, err, nil)
+		equal(t, groupIDs(gs), [][]string{{"200", "201", "204"}, {"202", "205"}, {"206", "207"}})
+	}
+
+	// Exported channels are moved out of their groups. Channels which aren't in
+	// the export stay in theirs, unless they would be left alone in it.
+	is(t, guilddb.ImportGuild(db, valid()), nil)
+
+	gs, err := db.ChannelGroups(guildID)
+	is(t, err, nil)
+	equal(t, groupIDs(gs), [][]string{{"206", "207"}, {"200", "201"}, {"202", "203"}})
+	_, err = db.ChannelGroup(guildID, c5.ID)
+	is(t, err, guilddb.ErrNotFound)
+	_, err = db.ChannelGroup(guildID, c6.ID)
+	is(t, err, guilddb.ErrNotFound)
+
+	c1.Language = translator.PT
+	r, err := db.Channel(guildID, c1.ID)
+	is(t, err, nil)
+	equal(t, r, c1)
+	r, err = db.Channel(guildID, c5.ID)
+	is(t, err, nil)
+	equal(t, r, c5)
+}
+
+func groupIDs(gs []guilddb.ChannelGroup) [][]string {
+	ids := make([][]string, len(gs))
+	for i, g := range gs {
+		for _, c := range g {
+			ids[i] = append(ids[i], c.ID)
+		}
+	}
+	return ids
+}
+
+// Inserts channels and messages from many goroutines, which must not fail or
+// lose any object.
+func testConcurrency(t *testing.T, db guilddb.GuildDB[Config]) {
+	insertGuild(t, db)
+
+	const channels, messages = 8, 20
+
+	var wg sync.WaitGroup
+	errs := make(chan error, channels*(messages+1))
+	g := make(guilddb.ChannelGroup, channels)
+	for i := range g {
+		g[i] = guilddb.NewChannel(guildID, strconv.Itoa(200+i), translator.EN)
+
+		wg.Add(1)
+		go func(c guilddb.Channel) {
+			defer wg.Done()
+
+			if err := db.ChannelInsert(c); err != nil {
+				errs <- err
+				return
+			}
+			for j := 0; j < messages; j++ {
+				m := guilddb.NewMessage(guildID, c.ID, strconv.Itoa(300+j), c.Language)
+				if err := db.MessageInsert(m); err != nil {
+					errs <- err
+				}
+				if _, err := db.Message(guildID, c.ID, m.ID); err != nil {
+					errs <- err
+				}
+			}
+		}(g[i])
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		is(t, err, nil)
+	}
+
+	is(t, db.ChannelGroupInsert(g), nil)
+	r, err := db.ChannelGroup(guildID, g[0].ID)
+	is(t, err, nil)
+	equal(t, r, g)
+
+	for _, c := range g {
+		is(t, db.MessageDeleteFromChannel(c), nil)
+	}
+}
+
+func insertGuild(t *testing.T, db guilddb.GuildDB[Config]) {
+	t.Helper()
+	is(t, db.GuildInsert(guilddb.NewGuild(guildID, Config{"Guild"})), nil)
+}
+
+func insertChannel(
+	t *testing.T,
+	db guilddb.GuildDB[Config],
+	ID string,
+	lang translator.Language,
+) guilddb.Channel {
+	t.Helper()
+	c := guilddb.NewChannel(guildID, ID, lang)
+	is(t, db.ChannelInsert(c), nil)
+	return c
+}
+
+func flagged(f guilddb.TranslationFlag, flags int) guilddb.FlaggedTranslation {
+	return guilddb.FlaggedTranslation{
+		GuildID:         f.GuildID,
+		ChannelID:       f.ChannelID,
+		MessageID:       f.MessageID,
+		OriginChannelID: f.OriginChannelID,
+		OriginID:        f.OriginID,
+		From:            f.From,
+		To:              f.To,
+		Provider:        f.Provider,
+		Flags:           flags,
+	}
+}
+
+// Fails the test if err isn't target, a nil target expects no error.
+func is(t *testing.T, err, target error) {
+	t.Helper()
+	if target == nil && err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	} else if target != nil && !errors.Is(err, target) {
+		t.Fatalf("Expected error %q, got %v", target, err)
+	}
+}
+
+func equal[T any](t *testing.T, got, expected T) {
+	t.Helper()
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("Expected %+v, got %+v", expected, got)
+	}
+}
@@ -0,0 +1,849 @@
+package guilddb
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"forge.capytal.company/capytal/dislate/translator"
+)
+
+// MemoryDB stores guilds in memory, it is safe for concurrent use and meant
+// for tests and short-lived instances of the bot. Objects are copied when
+// stored and returned, so changing them doesn't change the database.
+type MemoryDB[C any] struct {
+	mu sync.RWMutex
+
+	guilds   map[string][]byte
+	channels map[channelKey]Channel
+	messages map[messageKey]Message
+	// Group ID of each Channel in a group.
+	groups    map[channelKey]int64
+	nextGroup int64
+	glossary  map[glossaryKey]GlossaryTerm
+	flags     map[flagKey]TranslationFlag
+}
+
+type channelKey struct {
+	guildID string
+	ID      string
+}
+
+type messageKey struct {
+	guildID   string
+	channelID string
+	ID        string
+}
+
+type glossaryKey struct {
+	guildID string
+	// Lowercased, since terms are case-insensitive.
+	term     string
+	language translator.Language
+}
+
+type flagKey struct {
+	guildID   string
+	channelID string
+	messageID string
+	userID    string
+}
+
+func NewMemoryDB[C any]() *MemoryDB[C] {
+	return &MemoryDB[C]{
+		guilds:   make(map[string][]byte),
+		channels: make(map[channelKey]Channel),
+		messages: make(map[messageKey]Message),
+		groups:   make(map[channelKey]int64),
+		glossary: make(map[glossaryKey]GlossaryTerm),
+		flags:    make(map[flagKey]TranslationFlag),
+	}
+}
+
+// The database is locked while f runs, which changes a copy of it that replaces
+// the database if f succeeds.
+func (db *MemoryDB[C]) Tx(f func(tx GuildDB[C]) error) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	tx := &MemoryDB[C]{
+		guilds:    maps.Clone(db.guilds),
+		channels:  maps.Clone(db.channels),
+		messages:  maps.Clone(db.messages),
+		groups:    maps.Clone(db.groups),
+		nextGroup: db.nextGroup,
+		glossary:  maps.Clone(db.glossary),
+		flags:     maps.Clone(db.flags),
+	}
+	if err := f(tx); err != nil {
+		return err
+	}
+
+	db.guilds = tx.guilds
+	db.channels = tx.channels
+	db.messages = tx.messages
+	db.groups = tx.groups
+	db.nextGroup = tx.nextGroup
+	db.glossary = tx.glossary
+	db.flags = tx.flags
+
+	return nil
+}
+
+func (db *MemoryDB[C]) Message(guildID, channelID, messageID string) (Message, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	m, ok := db.messages[messageKey{guildID, channelID, messageID}]
+	if !ok {
+		return Message{}, errors.Join(
+			ErrNotFound,
+			fmt.Errorf("Message %s not found in channel %s", messageID, channelID),
+		)
+	}
+
+	return copyMessage(m), nil
+}
+
+func (db *MemoryDB[C]) MessagesWithOrigin(
+	guildID, originChannelID, originID string,
+) ([]Message, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	ms := db.messagesWithOrigin(guildID, originChannelID, originID)
+	if len(ms) == 0 {
+		return ms, errors.Join(
+			ErrNotFound,
+			fmt.Errorf("Message %s has no translations", originID),
+		)
+	}
+
+	for i, m := range ms {
+		ms[i] = copyMessage(m)
+	}
+	return ms, nil
+}
+
+func (db *MemoryDB[C]) MessageWithOriginByLang(
+	guildID, originChannelID, originID string,
+	language translator.Language,
+) (Message, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	for _, m := range db.messagesWithOrigin(guildID, originChannelID, originID) {
+		if m.Language == language {
+			return copyMessage(m), nil
+		}
+	}
+
+	return Message{}, errors.Join(
+		ErrNotFound,
+		fmt.Errorf("Message %s has no translation to %s", originID, language),
+	)
+}
+
+func (db *MemoryDB[C]) MessageInsert(m Message) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, ok := db.channels[channelKey{m.GuildID, m.ChannelID}]; !ok {
+		return errors.Join(
+			ErrPreconditionFailed,
+			fmt.Errorf("Channel %s doesn't exists in the database", m.ChannelID),
+		)
+	}
+
+	k := messageKey{m.GuildID, m.ChannelID, m.ID}
+	if _, ok := db.messages[k]; ok {
+		return ErrNoAffect
+	}
+	db.messages[k] = copyMessage(m)
+
+	return nil
+}
+
+func (db *MemoryDB[C]) MessageUpdate(m Message) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	k := messageKey{m.GuildID, m.ChannelID, m.ID}
+	old, ok := db.messages[k]
+	if !ok {
+		return ErrNoAffect
+	}
+	m.Timestamp = old.Timestamp
+	db.messages[k] = copyMessage(m)
+
+	return nil
+}
+
+func (db *MemoryDB[C]) MessageDelete(m Message) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, t := range db.messagesWithOrigin(m.GuildID, m.ChannelID, m.ID) {
+		delete(db.messages, messageKey{t.GuildID, t.ChannelID, t.ID})
+	}
+
+	k := messageKey{m.GuildID, m.ChannelID, m.ID}
+	if _, ok := db.messages[k]; !ok {
+		return ErrNoAffect
+	}
+	delete(db.messages, k)
+
+	return nil
+}
+
+func (db *MemoryDB[C]) MessageDeleteFromChannel(c Channel) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	n := 0
+	for k, m := range db.messages {
+		if k.guildID != c.GuildID {
+			continue
+		}
+		if k.channelID == c.ID {
+			delete(db.messages, k)
+			n++
+		} else if m.OriginChannelID != nil && *m.OriginChannelID == c.ID {
+			delete(db.messages, k)
+		}
+	}
+
+	if n == 0 {
+		return ErrNoAffect
+	}
+	return nil
+}
+
+func (db *MemoryDB[C]) Messages(guildID string) ([]Message, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var ms []Message
+	for _, m := range db.messages {
+		if m.GuildID == guildID {
+			ms = append(ms, copyMessage(m))
+		}
+	}
+
+	if len(ms) == 0 {
+		return ms, errors.Join(ErrNotFound, fmt.Errorf("Guild %s has no messages", guildID))
+	}
+
+	slices.SortFunc(ms, func(a, b Message) int {
+		if (a.OriginID == nil) != (b.OriginID == nil) {
+			if a.OriginID == nil {
+				return -1
+			}
+			return 1
+		}
+		if c := a.Timestamp.Compare(b.Timestamp); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(a.ChannelID, b.ChannelID); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return ms, nil
+}
+
+func (db *MemoryDB[C]) MessagesPrune(guildID string, before time.Time, limit int) (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var ms []Message
+	for _, m := range db.messages {
+		if m.GuildID == guildID && m.OriginID == nil && m.Timestamp.Before(before) {
+			ms = append(ms, m)
+		}
+	}
+	slices.SortFunc(ms, func(a, b Message) int {
+		return a.Timestamp.Compare(b.Timestamp)
+	})
+	if len(ms) > limit {
+		ms = ms[:limit]
+	}
+
+	for _, m := range ms {
+		for _, t := range db.messagesWithOrigin(m.GuildID, m.ChannelID, m.ID) {
+			delete(db.messages, messageKey{t.GuildID, t.ChannelID, t.ID})
+		}
+		delete(db.messages, messageKey{m.GuildID, m.ChannelID, m.ID})
+	}
+
+	return len(ms), nil
+}
+
+// Returns the translations of the origin message sorted by channel and ID.
+// The caller must hold the lock.
+func (db *MemoryDB[C]) messagesWithOrigin(guildID, originChannelID, originID string) []Message {
+	var ms []Message
+	for _, m := range db.messages {
+		if m.GuildID == guildID &&
+			m.OriginChannelID != nil && *m.OriginChannelID == originChannelID &&
+			m.OriginID != nil && *m.OriginID == originID {
+			ms = append(ms, m)
+		}
+	}
+
+	slices.SortFunc(ms, func(a, b Message) int {
+		return cmp.Or(cmp.Compare(a.ChannelID, b.ChannelID), cmp.Compare(a.ID, b.ID))
+	})
+
+	return ms
+}
+
+func (db *MemoryDB[C]) Channel(guildID, ID string) (Channel, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	c, ok := db.channels[channelKey{guildID, ID}]
+	if !ok {
+		return Channel{}, errors.Join(ErrNotFound, fmt.Errorf("Channel %s not found", ID))
+	}
+
+	return c, nil
+}
+
+func (db *MemoryDB[C]) Channels(guildID string) ([]Channel, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var cs []Channel
+	for k, c := range db.channels {
+		if k.guildID == guildID {
+			cs = append(cs, c)
+		}
+	}
+
+	if len(cs) == 0 {
+		return cs, errors.Join(ErrNotFound, fmt.Errorf("Guild %s has no channels", guildID))
+	}
+
+	slices.SortFunc(cs, func(a, b Channel) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return cs, nil
+}
+
+func (db *MemoryDB[C]) ChannelInsert(c Channel) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, ok := db.guilds[c.GuildID]; !ok {
+		return errors.Join(
+			ErrPreconditionFailed,
+			fmt.Errorf("Guild %s doesn't exists in the database", c.GuildID),
+		)
+	}
+
+	k := channelKey{c.GuildID, c.ID}
+	if _, ok := db.channels[k]; ok {
+		return ErrNoAffect
+	}
+	db.channels[k] = c
+
+	return nil
+}
+
+func (db *MemoryDB[C]) ChannelUpdate(c Channel) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	k := channelKey{c.GuildID, c.ID}
+	if _, ok := db.channels[k]; !ok {
+		return ErrNoAffect
+	}
+	db.channels[k] = c
+
+	return nil
+}
+
+func (db *MemoryDB[C]) ChannelDelete(c Channel) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	k := channelKey{c.GuildID, c.ID}
+	if _, ok := db.groups[k]; ok {
+		return errors.Join(ErrPreconditionFailed, fmt.Errorf("Channel %s is in a group", c.ID))
+	}
+	for mk := range db.messages {
+		if mk.guildID == c.GuildID && mk.channelID == c.ID {
+			return errors.Join(ErrPreconditionFailed, fmt.Errorf("Channel %s has messages", c.ID))
+		}
+	}
+
+	if _, ok := db.channels[k]; !ok {
+		return ErrNoAffect
+	}
+	delete(db.channels, k)
+
+	return nil
+}
+
+func (db *MemoryDB[C]) ChannelGroup(guildID, channelID string) (ChannelGroup, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	id, ok := db.groups[channelKey{guildID, channelID}]
+	if !ok {
+		return ChannelGroup{}, errors.Join(
+			ErrNotFound,
+			fmt.Errorf("Channel %s is not in a group", channelID),
+		)
+	}
+
+	var g ChannelGroup
+	for k, gid := range db.groups {
+		if gid != id {
+			continue
+		}
+		if c, ok := db.channels[k]; ok {
+			g = append(g, c)
+		}
+	}
+
+	if len(g) == 0 {
+		return ChannelGroup{}, errors.Join(
+			ErrNotFound,
+			fmt.Errorf("Channels of the group of %s not found", channelID),
+		)
+	}
+
+	slices.SortFunc(g, func(a, b Channel) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return g, nil
+}
+
+func (db *MemoryDB[C]) ChannelGroups(guildID string) ([]ChannelGroup, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	groups := make(map[int64]ChannelGroup)
+	for k, id := range db.groups {
+		if k.guildID != guildID {
+			continue
+		}
+		if c, ok := db.channels[k]; ok {
+			groups[id] = append(groups[id], c)
+		}
+	}
+
+	if len(groups) == 0 {
+		return []ChannelGroup{}, errors.Join(
+			ErrNotFound,
+			fmt.Errorf("Guild %s has no groups", guildID),
+		)
+	}
+
+	ids := make([]int64, 0, len(groups))
+	for id := range groups {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	gs := make([]ChannelGroup, 0, len(groups))
+	for _, id := range ids {
+		g := groups[id]
+		slices.SortFunc(g, func(a, b Channel) int {
+			return cmp.Compare(a.ID, b.ID)
+		})
+		gs = append(gs, g)
+	}
+
+	return gs, nil
+}
+
+func (db *MemoryDB[C]) ChannelGroupInsert(g ChannelGroup) error {
+	if len(g) == 0 {
+		return ErrNoAffect
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for i, c := range g {
+		if _, ok := db.groups[channelKey{c.GuildID, c.ID}]; ok ||
+			slices.ContainsFunc(g[:i], func(o Channel) bool { return o.ID == c.ID }) {
+			return errors.Join(
+				ErrPreconditionFailed,
+				fmt.Errorf("Channel %s is already in a group", c.ID),
+			)
+		}
+	}
+
+	db.nextGroup++
+	for _, c := range g {
+		db.groups[channelKey{c.GuildID, c.ID}] = db.nextGroup
+	}
+
+	return nil
+}
+
+func (db *MemoryDB[C]) ChannelGroupAdd(member, c Channel) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	id, ok := db.groups[channelKey{member.GuildID, member.ID}]
+	if !ok {
+		return errors.Join(ErrNotFound, fmt.Errorf("Channel %s is not in a group", member.ID))
+	}
+
+	k := channelKey{c.GuildID, c.ID}
+	if _, ok := db.groups[k]; ok {
+		return errors.Join(
+			ErrPreconditionFailed,
+			fmt.Errorf("Channel %s is already in a group", c.ID),
+		)
+	}
+	db.groups[k] = id
+
+	return nil
+}
+
+func (db *MemoryDB[C]) ChannelGroupRemove(c Channel) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	k := channelKey{c.GuildID, c.ID}
+	id, ok := db.groups[k]
+	if !ok {
+		return ErrNoAffect
+	}
+	delete(db.groups, k)
+
+	var left []channelKey
+	for k, gid := range db.groups {
+		if gid == id {
+			left = append(left, k)
+		}
+	}
+
+	// A single Channel has nothing to be translated to
+	if len(left) < 2 {
+		for _, k := range left {
+			delete(db.groups, k)
+		}
+	}
+
+	return nil
+}
+
+func (db *MemoryDB[C]) ChannelGroupMerge(member, c Channel) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	id, ok := db.groups[channelKey{member.GuildID, member.ID}]
+	if !ok {
+		return errors.Join(ErrNotFound, fmt.Errorf("Channel %s is not in a group", member.ID))
+	}
+	old, ok := db.groups[channelKey{c.GuildID, c.ID}]
+	if !ok {
+		return errors.Join(ErrNotFound, fmt.Errorf("Channel %s is not in a group", c.ID))
+	} else if id == old {
+		return ErrNoAffect
+	}
+
+	for k, gid := range db.groups {
+		if gid == old {
+			db.groups[k] = id
+		}
+	}
+
+	return nil
+}
+
+func (db *MemoryDB[C]) ChannelGroupDelete(g ChannelGroup) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var ids []int64
+	for _, c := range g {
+		if id, ok := db.groups[channelKey{c.GuildID, c.ID}]; ok && !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+
+	if len(ids) == 0 {
+		return ErrNoAffect
+	}
+
+	for k, id := range db.groups {
+		if slices.Contains(ids, id) {
+			delete(db.groups, k)
+		}
+	}
+
+	return nil
+}
+
+func (db *MemoryDB[C]) GlossaryTerms(guildID string) ([]GlossaryTerm, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var ts []GlossaryTerm
+	for k, t := range db.glossary {
+		if k.guildID == guildID {
+			ts = append(ts, copyGlossaryTerm(t))
+		}
+	}
+
+	if len(ts) == 0 {
+		return ts, errors.Join(ErrNotFound, fmt.Errorf("Guild %s has no glossary terms", guildID))
+	}
+
+	slices.SortFunc(ts, func(a, b GlossaryTerm) int {
+		return cmp.Or(
+			cmp.Compare(strings.ToLower(a.Term), strings.ToLower(b.Term)),
+			cmp.Compare(a.Language, b.Language),
+		)
+	})
+
+	return ts, nil
+}
+
+func (db *MemoryDB[C]) GlossaryTermInsert(t GlossaryTerm) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	k := glossaryKey{t.GuildID, strings.ToLower(t.Term), t.Language}
+	if _, ok := db.glossary[k]; ok {
+		return ErrNoAffect
+	}
+	db.glossary[k] = copyGlossaryTerm(t)
+
+	return nil
+}
+
+func (db *MemoryDB[C]) GlossaryTermUpdate(t GlossaryTerm) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	k := glossaryKey{t.GuildID, strings.ToLower(t.Term), t.Language}
+	o, ok := db.glossary[k]
+	if !ok {
+		return ErrNoAffect
+	}
+
+	// Only the replacement is updated, the term keeps its original case
+	o.Replacement = copyGlossaryTerm(t).Replacement
+	db.glossary[k] = o
+
+	return nil
+}
+
+func (db *MemoryDB[C]) GlossaryTermDelete(t GlossaryTerm) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	k := glossaryKey{t.GuildID, strings.ToLower(t.Term), t.Language}
+	if _, ok := db.glossary[k]; !ok {
+		return ErrNoAffect
+	}
+	delete(db.glossary, k)
+
+	return nil
+}
+
+func (db *MemoryDB[C]) TranslationFlagInsert(f TranslationFlag) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	k := flagKey{f.GuildID, f.ChannelID, f.MessageID, f.UserID}
+	if _, ok := db.flags[k]; ok {
+		return ErrNoAffect
+	}
+	db.flags[k] = f
+
+	return nil
+}
+
+func (db *MemoryDB[C]) TranslationFlagDelete(f TranslationFlag) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	k := flagKey{f.GuildID, f.ChannelID, f.MessageID, f.UserID}
+	if _, ok := db.flags[k]; !ok {
+		return ErrNoAffect
+	}
+	delete(db.flags, k)
+
+	return nil
+}
+
+func (db *MemoryDB[C]) FlaggedTranslations(
+	guildID string,
+	filter FlagFilter,
+	limit int,
+) ([]FlaggedTranslation, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	byMessage := make(map[messageKey]*FlaggedTranslation)
+	for _, f := range db.flags {
+		if f.GuildID != guildID ||
+			(filter.Provider != "" && !strings.EqualFold(f.Provider, filter.Provider)) ||
+			(filter.From != "" && f.From != filter.From) ||
+			(filter.To != "" && f.To != filter.To) {
+			continue
+		}
+
+		k := messageKey{f.GuildID, f.ChannelID, f.MessageID}
+		if ft, ok := byMessage[k]; ok {
+			ft.Flags++
+			continue
+		}
+		byMessage[k] = &FlaggedTranslation{
+			f.GuildID,
+			f.ChannelID,
+			f.MessageID,
+			f.OriginChannelID,
+			f.OriginID,
+			f.From,
+			f.To,
+			f.Provider,
+			1,
+		}
+	}
+
+	fs := make([]FlaggedTranslation, 0, len(byMessage))
+	for _, f := range byMessage {
+		fs = append(fs, *f)
+	}
+
+	slices.SortFunc(fs, func(a, b FlaggedTranslation) int {
+		return cmp.Or(cmp.Compare(b.Flags, a.Flags), cmp.Compare(b.MessageID, a.MessageID))
+	})
+
+	if limit >= 0 && len(fs) > limit {
+		fs = fs[:limit]
+	}
+
+	if len(fs) == 0 {
+		return fs, errors.Join(ErrNotFound, fmt.Errorf("Guild %s has no flagged translations", guildID))
+	}
+
+	return fs, nil
+}
+
+func (db *MemoryDB[C]) Guild(ID string) (Guild[C], error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	j, ok := db.guilds[ID]
+	if !ok {
+		return Guild[C]{}, errors.Join(ErrNotFound, fmt.Errorf("Guild %s not found", ID))
+	}
+
+	var c C
+	if err := json.Unmarshal(j, &c); err != nil {
+		return Guild[C]{}, errors.Join(ErrConfigParsing, err)
+	}
+
+	return Guild[C]{ID, c}, nil
+}
+
+func (db *MemoryDB[C]) GuildInsert(g Guild[C]) error {
+	j, err := marshalConfig(g.Config)
+	if err != nil {
+		return err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, ok := db.guilds[g.ID]; ok {
+		return ErrNoAffect
+	}
+	db.guilds[g.ID] = j
+
+	return nil
+}
+
+func (db *MemoryDB[C]) GuildUpdate(g Guild[C]) error {
+	j, err := marshalConfig(g.Config)
+	if err != nil {
+		return err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, ok := db.guilds[g.ID]; !ok {
+		return ErrNoAffect
+	}
+	db.guilds[g.ID] = j
+
+	return nil
+}
+
+func (db *MemoryDB[C]) GuildDelete(g Guild[C]) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for k := range db.channels {
+		if k.guildID == g.ID {
+			return errors.Join(ErrPreconditionFailed, fmt.Errorf("Guild %s has channels", g.ID))
+		}
+	}
+	for k := range db.glossary {
+		if k.guildID == g.ID {
+			return errors.Join(
+				ErrPreconditionFailed,
+				fmt.Errorf("Guild %s has glossary terms", g.ID),
+			)
+		}
+	}
+	for k := range db.flags {
+		if k.guildID == g.ID {
+			return errors.Join(
+				ErrPreconditionFailed,
+				fmt.Errorf("Guild %s has flagged translations", g.ID),
+			)
+		}
+	}
+
+	if _, ok := db.guilds[g.ID]; !ok {
+		return ErrNoAffect
+	}
+	delete(db.guilds, g.ID)
+
+	return nil
+}
+
+func copyMessage(m Message) Message {
+	m.OriginChannelID = copyString(m.OriginChannelID)
+	m.OriginID = copyString(m.OriginID)
+	m.Provider = copyString(m.Provider)
+	// Timestamps are stored with millisecond precision, as in SQL databases
+	m.Timestamp = time.UnixMilli(m.Timestamp.UnixMilli())
+	return m
+}
+
+func copyGlossaryTerm(t GlossaryTerm) GlossaryTerm {
+	t.Replacement = copyString(t.Replacement)
+	return t
+}
+
+func copyString(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	c := *s
+	return &c
+}
@@ -0,0 +1,11 @@
+package guilddb_test
+
+import (
+	"testing"
+
+	"forge.capytal.company/capytal/dislate/guilddb/guilddbtest"
+)
+
+func TestMemoryDB(t *testing.T) {
+	guilddbtest.Run(t, guilddbtest.Memory)
+}
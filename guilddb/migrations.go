@@ -0,0 +1,137 @@
+package guilddb
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// A migration changes the schema of a database from the previous version to its
+// own version. Migrations are applied in order, each one inside its own
+// transaction, and are never changed after being released: new columns and
+// tables need a new migration.
+type migration struct {
+	version     int
+	description string
+	up          func(tx *sql.Tx) error
+}
+
+// Creates the schema_version table, which has a row for each applied migration.
+const schemaVersionTable = `
+	CREATE TABLE IF NOT EXISTS schema_version (
+		Version     integer NOT NULL,
+		Description text    NOT NULL,
+		PRIMARY KEY(Version)
+	);
+`
+
+// Applies all migrations newer than the database's schema version, in order.
+// If lock isn't empty, it is executed at the start of each migration's
+// transaction, so many instances sharing a database don't apply a migration
+// twice.
+//
+// Will return ErrNewerSchema if the database was migrated by a newer version,
+// ErrMigrationFailed if a migration couldn't be applied or ErrInternal.
+func migrate(db *sql.DB, lock string, migrations []migration) error {
+	if _, err := db.Exec(schemaVersionTable); err != nil {
+		return errors.Join(ErrInternal, err)
+	}
+
+	v, err := schemaVersion(db)
+	if err != nil {
+		return err
+	}
+
+	latest := 0
+	for i, m := range migrations {
+		if m.version != i+1 {
+			return errors.Join(
+				ErrMigrationFailed,
+				fmt.Errorf("Migration %d is out of order, expected version %d", m.version, i+1),
+			)
+		}
+		latest = m.version
+	}
+
+	if v > latest {
+		return errors.Join(
+			ErrNewerSchema,
+			fmt.Errorf("Database is at version %d, latest known version is %d", v, latest),
+		)
+	}
+
+	for _, m := range migrations[v:] {
+		if err := applyMigration(db, lock, m); err != nil {
+			return errors.Join(
+				ErrMigrationFailed,
+				fmt.Errorf("Failed to apply migration %d (%s)", m.version, m.description),
+				err,
+			)
+		}
+	}
+
+	return nil
+}
+
+func applyMigration(db *sql.DB, lock string, m migration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if lock != "" {
+		if _, err := tx.Exec(lock); err != nil {
+			return err
+		}
+	}
+
+	// Another instance may have applied it while waiting for the lock
+	var n int
+	if err := tx.QueryRow(`
+		SELECT COUNT(*) FROM schema_version
+			WHERE Version = $1
+	`, m.version).Scan(&n); err != nil {
+		return err
+	} else if n > 0 {
+		return nil
+	}
+
+	if err := m.up(tx); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO schema_version (Version, Description)
+			VALUES ($1, $2)
+	`, m.version, m.description); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Returns the version of the latest migration applied to the database, or 0 if
+// none was applied.
+func schemaVersion(db *sql.DB) (int, error) {
+	var v sql.NullInt64
+	if err := db.QueryRow(`SELECT MAX(Version) FROM schema_version`).Scan(&v); err != nil {
+		return 0, errors.Join(ErrInternal, err)
+	}
+	return int(v.Int64), nil
+}
+
+// Runs each statement in the transaction, stopping at the first error.
+func execAll(tx *sql.Tx, statements ...string) error {
+	for _, s := range statements {
+		if _, err := tx.Exec(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var (
+	ErrNewerSchema     = errors.New("Database schema is newer than the supported version")
+	ErrMigrationFailed = errors.New("Failed to migrate database schema")
+)
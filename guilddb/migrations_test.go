@@ -0,0 +1,192 @@
+package guilddb
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"forge.capytal.company/capytal/dislate/translator"
+)
+
+type fixtureConfig struct {
+	Name string `json:"name"`
+}
+
+// Opens a new SQLiteDB in a temporary file, with the statements of the fixture
+// in testdata executed if it isn't empty.
+func openFixture(t *testing.T, fixture string) *SQLiteDB[fixtureConfig] {
+	t.Helper()
+
+	db, err := NewSQLiteDB[fixtureConfig]("file:" + filepath.Join(t.TempDir(), "guild.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %s", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if fixture == "" {
+		return db
+	}
+
+	b, err := os.ReadFile(filepath.Join("testdata", fixture))
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %s", err)
+	}
+	for _, s := range strings.Split(string(b), ";\n") {
+		if strings.TrimSpace(stripComments(s)) == "" {
+			continue
+		}
+		if _, err := db.sql.Exec(s); err != nil {
+			t.Fatalf("Failed to execute fixture statement %q: %s", s, err)
+		}
+	}
+
+	return db
+}
+
+func stripComments(s string) string {
+	var ls []string
+	for _, l := range strings.Split(s, "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(l), "--") {
+			ls = append(ls, l)
+		}
+	}
+	return strings.Join(ls, "\n")
+}
+
+func TestSQLiteMigrateBaseline(t *testing.T) {
+	db := openFixture(t, "baseline.sql")
+
+	if err := db.Prepare(); err != nil {
+		t.Fatalf("Failed to migrate database: %s", err)
+	}
+	checkVersion(t, db, len(sqliteMigrations))
+	checkBaseline(t, db)
+
+	// Preparing an up to date database changes nothing
+	if err := db.Prepare(); err != nil {
+		t.Fatalf("Failed to prepare migrated database: %s", err)
+	}
+	checkVersion(t, db, len(sqliteMigrations))
+	checkBaseline(t, db)
+}
+
+func checkBaseline(t *testing.T, db *SQLiteDB[fixtureConfig]) {
+	t.Helper()
+
+	g, err := db.Guild("100")
+	if err != nil {
+		t.Fatalf("Failed to get guild: %s", err)
+	} else if g.Config.Name != "Guild" {
+		t.Fatalf("Expected guild config to be kept, got %+v", g.Config)
+	}
+
+	// Channels get the defaults of the columns added afterwards
+	c, err := db.Channel("100", "201")
+	if err != nil {
+		t.Fatalf("Failed to get channel: %s", err)
+	} else if !reflect.DeepEqual(c, NewChannel("100", "201", translator.PT)) {
+		t.Fatalf("Expected channel with default options, got %+v", c)
+	}
+
+	gs, err := db.ChannelGroups("100")
+	if err != nil {
+		t.Fatalf("Failed to get groups: %s", err)
+	}
+	ids := make([][]string, len(gs))
+	for i, g := range gs {
+		for _, c := range g {
+			ids[i] = append(ids[i], c.ID)
+		}
+	}
+	expected := [][]string{{"200", "201"}, {"203", "204"}}
+	if !reflect.DeepEqual(ids, expected) {
+		t.Fatalf("Expected groups %v, got %v", expected, ids)
+	}
+
+	ms, err := db.Messages("100")
+	if err != nil {
+		t.Fatalf("Failed to get messages: %s", err)
+	} else if len(ms) != 3 {
+		t.Fatalf("Expected 3 messages, got %+v", ms)
+	}
+	for _, m := range ms {
+		if m.Timestamp.IsZero() || m.Timestamp.UnixMilli() == 0 {
+			t.Fatalf("Expected message %s to have a timestamp", m.ID)
+		}
+	}
+
+	tms, err := db.MessagesWithOrigin("100", "200", "300")
+	if err != nil {
+		t.Fatalf("Failed to get translated messages: %s", err)
+	} else if len(tms) != 1 || tms[0].ID != "301" || tms[0].Language != translator.PT {
+		t.Fatalf("Expected translated message 301, got %+v", tms)
+	}
+}
+
+func checkVersion(t *testing.T, db *SQLiteDB[fixtureConfig], expected int) {
+	t.Helper()
+	if v, err := db.SchemaVersion(); err != nil {
+		t.Fatalf("Failed to get schema version: %s", err)
+	} else if v != expected {
+		t.Fatalf("Expected schema version %d, got %d", expected, v)
+	}
+}
+
+func TestSQLiteMigrateNewerSchema(t *testing.T) {
+	db := openFixture(t, "")
+	if err := db.Prepare(); err != nil {
+		t.Fatalf("Failed to prepare database: %s", err)
+	}
+
+	if _, err := db.sql.Exec(`
+		INSERT INTO schema_version (Version, Description)
+			VALUES ($1, 'From the future')
+	`, len(sqliteMigrations)+1); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if err := db.Prepare(); !errors.Is(err, ErrNewerSchema) {
+		t.Fatalf("Expected error %q, got %v", ErrNewerSchema, err)
+	}
+}
+
+func TestSQLiteMigrateRollback(t *testing.T) {
+	db := openFixture(t, "")
+	if err := db.Prepare(); err != nil {
+		t.Fatalf("Failed to prepare database: %s", err)
+	}
+
+	ms := slices.Clone(sqliteMigrations)
+	ms = append(ms, migration{len(ms) + 1, "Failing migration", func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`CREATE TABLE partial (ID text NOT NULL)`); err != nil {
+			return err
+		}
+		return errors.New("Migration failed")
+	}})
+	err := migrate(db.sql, "", ms)
+	if !errors.Is(err, ErrMigrationFailed) {
+		t.Fatalf("Expected error %q, got %v", ErrMigrationFailed, err)
+	}
+
+	// Nothing of the failed migration is kept
+	checkVersion(t, db, len(sqliteMigrations))
+	var n int
+	if err := db.sql.QueryRow(`
+		SELECT COUNT(*) FROM sqlite_master
+			WHERE "type" = 'table' AND "name" = 'partial'
+	`).Scan(&n); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	} else if n != 0 {
+		t.Fatal("Expected table of the failed migration to be rolled back")
+	}
+
+	outOfOrder := []migration{sqliteMigrations[1]}
+	if err := migrate(db.sql, "", outOfOrder); !errors.Is(err, ErrMigrationFailed) {
+		t.Fatalf("Expected error %q, got %v", ErrMigrationFailed, err)
+	}
+}
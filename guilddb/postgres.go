@@ -0,0 +1,934 @@
+package guilddb
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"forge.capytal.company/capytal/dislate/translator"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+// PostgresDB stores guilds in a PostgreSQL database, which can be shared by
+// many instances of the bot.
+type PostgresDB[C any] struct {
+	sql *sql.DB
+	// Transaction of a database scoped by Tx, nil otherwise.
+	tx *sql.Tx
+}
+
+// Returns if the data source name is a PostgreSQL connection URL.
+func IsPostgresDSN(dsn string) bool {
+	return strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://")
+}
+
+func NewPostgresDB[C any](dsn string) (*PostgresDB[C], error) {
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		return &PostgresDB[C]{}, err
+	}
+	return &PostgresDB[C]{db, nil}, nil
+}
+
+func (db *PostgresDB[C]) Close() error {
+	return db.sql.Close()
+}
+
+func (db *PostgresDB[C]) Tx(f func(tx GuildDB[C]) error) error {
+	return withTx(db.sql, db.tx, func(tx *sql.Tx) error {
+		return f(&PostgresDB[C]{db.sql, tx})
+	})
+}
+
+func (db *PostgresDB[C]) conn() querier {
+	if db.tx != nil {
+		return db.tx
+	}
+	return db.sql
+}
+
+// Key of the advisory lock held while migrating, so instances starting at the
+// same time don't apply the same migration.
+const postgresMigrationLock = 5472361
+
+// Migrates the database to the latest schema version.
+//
+// Will return ErrNewerSchema if the database was migrated by a newer version,
+// ErrMigrationFailed or ErrInternal.
+func (db *PostgresDB[C]) Prepare() error {
+	return migrate(
+		db.sql,
+		fmt.Sprintf("SELECT pg_advisory_xact_lock(%d)", postgresMigrationLock),
+		postgresMigrations,
+	)
+}
+
+// Returns the schema version of the database, 0 if it was never migrated.
+func (db *PostgresDB[C]) SchemaVersion() (int, error) {
+	return schemaVersion(db.sql)
+}
+
+var postgresMigrations = []migration{
+	{1, "Initial schema", postgresInitialSchema},
+	{2, "Add message timestamps", postgresMessageTimestamps},
+}
+
+// Glossary terms are case-insensitive, which is done with an unique index of
+// the lowercased term since there isn't a NOCASE collation.
+func postgresInitialSchema(tx *sql.Tx) error {
+	return execAll(tx, `
+		CREATE TABLE guilds (
+			ID     text NOT NULL,
+			Config text NOT NULL,
+			PRIMARY KEY(ID)
+		);
+	`, `
+		CREATE TABLE channels (
+			GuildID           text    NOT NULL,
+			ID                text    NOT NULL,
+			Language          text    NOT NULL,
+			AutoDetect        boolean NOT NULL DEFAULT false,
+			Formality         text    NOT NULL DEFAULT '',
+			PreserveProfanity boolean NOT NULL DEFAULT false,
+			PRIMARY KEY(GuildID, ID),
+			FOREIGN KEY(GuildID) REFERENCES guilds(ID)
+		);
+	`, `
+		CREATE TABLE groups (
+			ID      bigint NOT NULL GENERATED ALWAYS AS IDENTITY,
+			GuildID text   NOT NULL,
+			PRIMARY KEY(ID),
+			FOREIGN KEY(GuildID) REFERENCES guilds(ID)
+		);
+	`, `
+		CREATE TABLE group_members (
+			GuildID   text   NOT NULL,
+			ChannelID text   NOT NULL,
+			GroupID   bigint NOT NULL,
+			PRIMARY KEY(GuildID, ChannelID),
+			FOREIGN KEY(GroupID) REFERENCES groups(ID),
+			FOREIGN KEY(GuildID, ChannelID) REFERENCES channels(GuildID, ID)
+		);
+	`, `
+		CREATE INDEX group_members_groupid ON group_members (GroupID);
+	`, `
+		CREATE TABLE messages (
+			GuildID         text NOT NULL,
+			ChannelID       text NOT NULL,
+			ID              text NOT NULL,
+			Language        text NOT NULL,
+			OriginChannelID text,
+			OriginID        text,
+			Provider        text,
+			PRIMARY KEY(GuildID, ChannelID, ID),
+			FOREIGN KEY(GuildID, ChannelID) REFERENCES channels(GuildID, ID),
+			FOREIGN KEY(GuildID, OriginChannelID, OriginID) REFERENCES messages(GuildID, ChannelID, ID)
+		);
+	`, `
+		CREATE INDEX messages_origin ON messages (GuildID, OriginChannelID, OriginID);
+	`, `
+		CREATE TABLE translationFlags (
+			GuildID         text NOT NULL,
+			ChannelID       text NOT NULL,
+			MessageID       text NOT NULL,
+			UserID          text NOT NULL,
+			OriginChannelID text NOT NULL,
+			OriginID        text NOT NULL,
+			"From"          text NOT NULL,
+			"To"            text NOT NULL,
+			Provider        text NOT NULL,
+			PRIMARY KEY(GuildID, ChannelID, MessageID, UserID),
+			FOREIGN KEY(GuildID) REFERENCES guilds(ID)
+		);
+	`, `
+		CREATE TABLE glossary (
+			GuildID     text NOT NULL,
+			Term        text NOT NULL,
+			Language    text NOT NULL,
+			Replacement text,
+			FOREIGN KEY(GuildID) REFERENCES guilds(ID)
+		);
+	`, `
+		CREATE UNIQUE INDEX glossary_term ON glossary (GuildID, lower(Term), Language);
+	`, `
+		CREATE TABLE translationCache (
+			Key         text   NOT NULL,
+			Translation text   NOT NULL,
+			CreatedAt   bigint NOT NULL,
+			ExpiresAt   bigint NOT NULL,
+			PRIMARY KEY(Key)
+		);
+	`)
+}
+
+// Existing messages are timestamped with the time of the migration, so they
+// are only pruned after a full retention period.
+func postgresMessageTimestamps(tx *sql.Tx) error {
+	return execAll(tx, `
+		ALTER TABLE messages ADD COLUMN Timestamp bigint NOT NULL DEFAULT 0;
+	`, `
+		UPDATE messages SET Timestamp = (extract(epoch FROM now()) * 1000)::bigint;
+	`, `
+		CREATE INDEX messages_timestamp ON messages (GuildID, Timestamp);
+	`)
+}
+
+func (db *PostgresDB[C]) Message(guildID, channelID, messageID string) (Message, error) {
+	return db.selectMessage(`
+		WHERE GuildID = $1 AND ChannelID = $2 AND ID = $3
+	`, guildID, channelID, messageID)
+}
+
+func (db *PostgresDB[C]) MessagesWithOrigin(
+	guildID, originChannelID, originID string,
+) ([]Message, error) {
+	return db.selectMessages(`
+		WHERE GuildID = $1 AND OriginChannelID = $2 AND OriginID = $3
+	`, guildID, originChannelID, originID)
+}
+
+func (db *PostgresDB[C]) MessageWithOriginByLang(
+	guildID, originChannelID, originID string,
+	language translator.Language,
+) (Message, error) {
+	return db.selectMessage(`
+		WHERE GuildID = $1 AND OriginChannelID = $2 AND OriginID = $3 AND Language = $4
+	`, guildID, originChannelID, originID, language)
+}
+
+func (db *PostgresDB[C]) MessageInsert(m Message) error {
+	_, err := db.Channel(m.GuildID, m.ChannelID)
+	if errors.Is(err, ErrNotFound) {
+		return errors.Join(
+			ErrPreconditionFailed,
+			fmt.Errorf("Channel %s doesn't exists in the database", m.ChannelID),
+		)
+	} else if err != nil {
+		return errors.Join(
+			ErrInternal,
+			errors.New("Failed to check if Channel exists in the database"),
+			err,
+		)
+	}
+
+	r, err := db.conn().Exec(`
+		INSERT INTO messages
+			(GuildID, ChannelID, ID, Language, OriginChannelID, OriginID, Provider, Timestamp)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			ON CONFLICT DO NOTHING
+	`,
+		m.GuildID,
+		m.ChannelID,
+		m.ID,
+		m.Language,
+		m.OriginChannelID,
+		m.OriginID,
+		m.Provider,
+		unixMilli(m.Timestamp),
+	)
+
+	if err != nil {
+		return errors.Join(ErrInternal, err)
+	} else if rows, _ := r.RowsAffected(); rows == 0 {
+		return ErrNoAffect
+	}
+
+	return nil
+}
+
+func (db *PostgresDB[C]) MessageUpdate(m Message) error {
+	r, err := db.conn().Exec(`
+		UPDATE messages
+			SET Language = $1, OriginChannelID = $2, OriginID = $3, Provider = $4
+			WHERE GuildID = $5 AND ChannelID = $6 AND ID = $7
+	`, m.Language,
+		m.OriginChannelID,
+		m.OriginID,
+		m.Provider,
+		m.GuildID,
+		m.ChannelID,
+		m.ID,
+	)
+
+	if err != nil {
+		return errors.Join(ErrInternal, err)
+	} else if rows, _ := r.RowsAffected(); rows == 0 {
+		return ErrNoAffect
+	}
+
+	return nil
+}
+
+func (db *PostgresDB[C]) MessageDelete(m Message) error {
+	_, err := db.conn().Exec(`
+		DELETE FROM messages
+			WHERE GuildID = $1 AND OriginChannelID = $2 AND OriginID = $3
+	`, m.GuildID, m.ChannelID, m.ID)
+	if err != nil {
+		return errors.Join(ErrInternal, err)
+	}
+
+	r, err := db.conn().Exec(`
+		DELETE FROM messages
+			WHERE GuildID = $1 AND ChannelID = $2 AND ID = $3
+	`, m.GuildID, m.ChannelID, m.ID)
+
+	if err != nil {
+		return errors.Join(ErrInternal, err)
+	} else if rows, _ := r.RowsAffected(); rows == 0 {
+		return ErrNoAffect
+	}
+
+	return nil
+}
+
+func (db *PostgresDB[C]) MessageDeleteFromChannel(c Channel) error {
+	return withTx(db.sql, db.tx, func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`
+			DELETE FROM messages
+				WHERE GuildID = $1 AND OriginChannelID = $2
+		`, c.GuildID, c.ID); err != nil {
+			return errors.Join(ErrInternal, err)
+		}
+
+		r, err := tx.Exec(`
+			DELETE FROM messages
+				WHERE GuildID = $1 AND ChannelID = $2
+		`, c.GuildID, c.ID)
+
+		if err != nil {
+			return errors.Join(ErrInternal, err)
+		} else if rows, _ := r.RowsAffected(); rows == 0 {
+			return ErrNoAffect
+		}
+
+		return nil
+	})
+}
+
+func (db *PostgresDB[C]) Messages(guildID string) ([]Message, error) {
+	return db.selectMessages(`
+		WHERE GuildID = $1
+		ORDER BY OriginID IS NOT NULL, Timestamp, ChannelID, ID
+	`, guildID)
+}
+
+func (db *PostgresDB[C]) MessagesPrune(guildID string, before time.Time, limit int) (int, error) {
+	var n int
+	err := withTx(db.sql, db.tx, func(tx *sql.Tx) error {
+		var err error
+		n, err = pruneMessages(tx, guildID, before, limit)
+		return err
+	})
+	return n, err
+}
+
+func (db *PostgresDB[C]) selectMessage(query string, args ...any) (Message, error) {
+	var m Message
+	err := db.conn().QueryRow(fmt.Sprintf(`
+		SELECT GuildID, ChannelID, ID, Language, OriginChannelID, OriginID, Provider, Timestamp
+			FROM messages
+			%s
+	`, query), args...).
+		Scan(
+			&m.GuildID,
+			&m.ChannelID,
+			&m.ID,
+			&m.Language,
+			&m.OriginChannelID,
+			&m.OriginID,
+			&m.Provider,
+			(*unixMilli)(&m.Timestamp),
+		)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return m, errors.Join(ErrNotFound, err)
+	} else if err != nil {
+		return m, errors.Join(ErrInternal, err)
+	}
+
+	return m, nil
+}
+
+func (db *PostgresDB[C]) selectMessages(query string, args ...any) ([]Message, error) {
+	r, err := db.conn().Query(fmt.Sprintf(`
+		SELECT GuildID, ChannelID, ID, Language, OriginChannelID, OriginID, Provider, Timestamp
+			FROM messages
+			%s
+	`, query), args...)
+	if err != nil {
+		return []Message{}, errors.Join(ErrInternal, err)
+	}
+	defer r.Close()
+
+	var ms []Message
+	for r.Next() {
+		var m Message
+
+		err = r.Scan(
+			&m.GuildID,
+			&m.ChannelID,
+			&m.ID,
+			&m.Language,
+			&m.OriginChannelID,
+			&m.OriginID,
+			&m.Provider,
+			(*unixMilli)(&m.Timestamp),
+		)
+		if err != nil {
+			return ms, errors.Join(
+				ErrInternal,
+				fmt.Errorf("Query: %s\nArguments: %v", query, args),
+				err,
+			)
+		}
+
+		ms = append(ms, m)
+	}
+
+	if len(ms) == 0 {
+		return ms, errors.Join(
+			ErrNotFound,
+			fmt.Errorf("Query: %s\nArguments: %v", query, args),
+		)
+	}
+	return ms, r.Err()
+}
+
+func (db *PostgresDB[C]) Channel(guildID, ID string) (Channel, error) {
+	return db.selectChannel(`
+		WHERE GuildID = $1 AND ID = $2
+	`, guildID, ID)
+}
+
+func (db *PostgresDB[C]) Channels(guildID string) ([]Channel, error) {
+	return db.selectChannels(`
+		WHERE GuildID = $1
+		ORDER BY ID
+	`, guildID)
+}
+
+func (db *PostgresDB[C]) ChannelInsert(c Channel) error {
+	if err := checkGuildExists(db.conn(), c.GuildID); err != nil {
+		return err
+	}
+
+	r, err := db.conn().Exec(`
+		INSERT INTO channels
+			(GuildID, ID, Language, AutoDetect, Formality, PreserveProfanity)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT DO NOTHING
+	`, c.GuildID,
+		c.ID,
+		c.Language,
+		c.AutoDetect,
+		c.Options.Formality,
+		c.Options.PreserveProfanity,
+	)
+
+	if err != nil {
+		return errors.Join(ErrInternal, err)
+	} else if rows, _ := r.RowsAffected(); rows == 0 {
+		return ErrNoAffect
+	}
+
+	return nil
+}
+
+func (db *PostgresDB[C]) ChannelUpdate(c Channel) error {
+	r, err := db.conn().Exec(`
+		UPDATE channels
+			SET Language = $1, AutoDetect = $2, Formality = $3, PreserveProfanity = $4
+			WHERE GuildID = $5 AND ID = $6
+	`, c.Language,
+		c.AutoDetect,
+		c.Options.Formality,
+		c.Options.PreserveProfanity,
+		c.GuildID,
+		c.ID,
+	)
+
+	if err != nil {
+		return errors.Join(ErrInternal, err)
+	} else if rows, _ := r.RowsAffected(); rows == 0 {
+		return ErrNoAffect
+	}
+
+	return nil
+}
+
+func (db *PostgresDB[C]) ChannelDelete(c Channel) error {
+	return withTx(db.sql, db.tx, func(tx *sql.Tx) error {
+		if err := checkChannelUnreferenced(tx, c); err != nil {
+			return err
+		}
+
+		r, err := tx.Exec(`
+			DELETE FROM channels
+				WHERE GuildID = $1 AND ID = $2
+		`, c.GuildID, c.ID)
+
+		if err != nil {
+			return errors.Join(ErrInternal, err)
+		} else if rows, _ := r.RowsAffected(); rows == 0 {
+			return ErrNoAffect
+		}
+
+		return nil
+	})
+}
+
+func (db *PostgresDB[C]) ChannelGroup(guildID, channelID string) (ChannelGroup, error) {
+	cs, err := db.selectChannels(`
+		WHERE GuildID = $1 AND ID IN (
+			SELECT ChannelID FROM group_members
+				WHERE GroupID = (
+					SELECT GroupID FROM group_members
+						WHERE GuildID = $1 AND ChannelID = $2
+				)
+		)
+		ORDER BY ID
+	`, guildID, channelID)
+	if err != nil {
+		return ChannelGroup{}, err
+	}
+
+	return cs, nil
+}
+
+func (db *PostgresDB[C]) ChannelGroups(guildID string) ([]ChannelGroup, error) {
+	return selectGroups(db.conn(), guildID)
+}
+
+func (db *PostgresDB[C]) ChannelGroupInsert(g ChannelGroup) error {
+	if len(g) == 0 {
+		return ErrNoAffect
+	}
+
+	return withTx(db.sql, db.tx, func(tx *sql.Tx) error {
+		var id int64
+		err := tx.QueryRow(`
+			INSERT INTO groups (GuildID)
+				VALUES ($1)
+				RETURNING ID
+		`, g[0].GuildID).Scan(&id)
+		if err != nil {
+			return errors.Join(ErrInternal, err)
+		}
+
+		for _, c := range g {
+			if err := insertGroupMember(tx, id, c); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (db *PostgresDB[C]) ChannelGroupAdd(member, c Channel) error {
+	return withTx(db.sql, db.tx, func(tx *sql.Tx) error {
+		id, err := selectGroupID(tx, member)
+		if err != nil {
+			return err
+		}
+
+		return insertGroupMember(tx, id, c)
+	})
+}
+
+func (db *PostgresDB[C]) ChannelGroupRemove(c Channel) error {
+	return withTx(db.sql, db.tx, func(tx *sql.Tx) error {
+		var id int64
+		err := tx.QueryRow(`
+			DELETE FROM group_members
+				WHERE GuildID = $1 AND ChannelID = $2
+				RETURNING GroupID
+		`, c.GuildID, c.ID).Scan(&id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNoAffect
+		} else if err != nil {
+			return errors.Join(ErrInternal, err)
+		}
+
+		var n int
+		err = tx.QueryRow(`
+			SELECT COUNT(*) FROM group_members
+				WHERE GroupID = $1
+		`, id).Scan(&n)
+		if err != nil {
+			return errors.Join(ErrInternal, err)
+		}
+
+		// A single Channel has nothing to be translated to
+		if n < 2 {
+			if err := deleteGroup(tx, id); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (db *PostgresDB[C]) ChannelGroupMerge(member, c Channel) error {
+	return withTx(db.sql, db.tx, func(tx *sql.Tx) error {
+		return mergeGroups(tx, member, c)
+	})
+}
+
+func (db *PostgresDB[C]) ChannelGroupDelete(g ChannelGroup) error {
+	return withTx(db.sql, db.tx, func(tx *sql.Tx) error {
+		var ids []int64
+		for _, c := range g {
+			id, err := selectGroupID(tx, c)
+			if errors.Is(err, ErrNotFound) {
+				continue
+			} else if err != nil {
+				return err
+			}
+
+			if !slices.Contains(ids, id) {
+				ids = append(ids, id)
+			}
+		}
+
+		if len(ids) == 0 {
+			return ErrNoAffect
+		}
+
+		for _, id := range ids {
+			if err := deleteGroup(tx, id); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (db *PostgresDB[C]) selectChannel(query string, args ...any) (Channel, error) {
+	var c Channel
+	err := db.conn().QueryRow(fmt.Sprintf(`
+		SELECT GuildID, ID, Language, AutoDetect, Formality, PreserveProfanity FROM channels
+			%s
+	`, query), args...).Scan(
+		&c.GuildID,
+		&c.ID,
+		&c.Language,
+		&c.AutoDetect,
+		&c.Options.Formality,
+		&c.Options.PreserveProfanity,
+	)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return c, errors.Join(ErrNotFound, err)
+	} else if err != nil {
+		return c, errors.Join(ErrInternal, err)
+	}
+
+	return c, nil
+}
+
+func (db *PostgresDB[C]) selectChannels(query string, args ...any) ([]Channel, error) {
+	r, err := db.conn().Query(fmt.Sprintf(`
+		SELECT GuildID, ID, Language, AutoDetect, Formality, PreserveProfanity FROM channels
+			%s
+	`, query), args...)
+	if err != nil {
+		return []Channel{}, errors.Join(ErrInternal, err)
+	}
+	defer r.Close()
+
+	var cs []Channel
+	for r.Next() {
+		var c Channel
+
+		err = r.Scan(
+			&c.GuildID,
+			&c.ID,
+			&c.Language,
+			&c.AutoDetect,
+			&c.Options.Formality,
+			&c.Options.PreserveProfanity,
+		)
+		if err != nil {
+			return cs, errors.Join(
+				ErrInternal,
+				fmt.Errorf("Query: %s\nArguments: %v", query, args),
+				err,
+			)
+		}
+
+		cs = append(cs, c)
+	}
+
+	if len(cs) == 0 {
+		return cs, errors.Join(
+			ErrNotFound,
+			fmt.Errorf("Query: %s\nArguments: %v", query, args),
+		)
+	}
+	return cs, r.Err()
+}
+
+func (db *PostgresDB[C]) GlossaryTerms(guildID string) ([]GlossaryTerm, error) {
+	r, err := db.conn().Query(`
+		SELECT GuildID, Term, Language, Replacement FROM glossary
+			WHERE GuildID = $1
+			ORDER BY lower(Term), Language
+	`, guildID)
+	if err != nil {
+		return []GlossaryTerm{}, errors.Join(ErrInternal, err)
+	}
+	defer r.Close()
+
+	var ts []GlossaryTerm
+	for r.Next() {
+		var t GlossaryTerm
+
+		err = r.Scan(&t.GuildID, &t.Term, &t.Language, &t.Replacement)
+		if err != nil {
+			return ts, errors.Join(ErrInternal, err)
+		}
+
+		ts = append(ts, t)
+	}
+
+	if len(ts) == 0 {
+		return ts, errors.Join(ErrNotFound, fmt.Errorf("Guild %s has no glossary terms", guildID))
+	}
+	return ts, nil
+}
+
+func (db *PostgresDB[C]) GlossaryTermInsert(t GlossaryTerm) error {
+	r, err := db.conn().Exec(`
+		INSERT INTO glossary (GuildID, Term, Language, Replacement)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT DO NOTHING
+	`, t.GuildID, t.Term, t.Language, t.Replacement)
+
+	if err != nil {
+		return errors.Join(ErrInternal, err)
+	} else if rows, _ := r.RowsAffected(); rows == 0 {
+		return ErrNoAffect
+	}
+
+	return nil
+}
+
+func (db *PostgresDB[C]) GlossaryTermUpdate(t GlossaryTerm) error {
+	r, err := db.conn().Exec(`
+		UPDATE glossary
+			SET Replacement = $1
+			WHERE GuildID = $2 AND lower(Term) = lower($3) AND Language = $4
+	`, t.Replacement, t.GuildID, t.Term, t.Language)
+
+	if err != nil {
+		return errors.Join(ErrInternal, err)
+	} else if rows, _ := r.RowsAffected(); rows == 0 {
+		return ErrNoAffect
+	}
+
+	return nil
+}
+
+func (db *PostgresDB[C]) GlossaryTermDelete(t GlossaryTerm) error {
+	r, err := db.conn().Exec(`
+		DELETE FROM glossary
+			WHERE GuildID = $1 AND lower(Term) = lower($2) AND Language = $3
+	`, t.GuildID, t.Term, t.Language)
+
+	if err != nil {
+		return errors.Join(ErrInternal, err)
+	} else if rows, _ := r.RowsAffected(); rows == 0 {
+		return ErrNoAffect
+	}
+
+	return nil
+}
+
+func (db *PostgresDB[C]) TranslationFlagInsert(f TranslationFlag) error {
+	r, err := db.conn().Exec(`
+		INSERT INTO translationFlags
+			(GuildID, ChannelID, MessageID, UserID, OriginChannelID, OriginID, "From", "To", Provider)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			ON CONFLICT DO NOTHING
+	`, f.GuildID,
+		f.ChannelID,
+		f.MessageID,
+		f.UserID,
+		f.OriginChannelID,
+		f.OriginID,
+		f.From,
+		f.To,
+		f.Provider,
+	)
+
+	if err != nil {
+		return errors.Join(ErrInternal, err)
+	} else if rows, _ := r.RowsAffected(); rows == 0 {
+		return ErrNoAffect
+	}
+
+	return nil
+}
+
+func (db *PostgresDB[C]) TranslationFlagDelete(f TranslationFlag) error {
+	r, err := db.conn().Exec(`
+		DELETE FROM translationFlags
+			WHERE GuildID = $1 AND ChannelID = $2 AND MessageID = $3 AND UserID = $4
+	`, f.GuildID, f.ChannelID, f.MessageID, f.UserID)
+
+	if err != nil {
+		return errors.Join(ErrInternal, err)
+	} else if rows, _ := r.RowsAffected(); rows == 0 {
+		return ErrNoAffect
+	}
+
+	return nil
+}
+
+// All flags of a message have the same origin, languages and provider, MAX
+// only picks one of them since Postgres doesn't allow ungrouped columns.
+func (db *PostgresDB[C]) FlaggedTranslations(
+	guildID string,
+	filter FlagFilter,
+	limit int,
+) ([]FlaggedTranslation, error) {
+	r, err := db.conn().Query(`
+		SELECT GuildID, ChannelID, MessageID, MAX(OriginChannelID), MAX(OriginID),
+			MAX("From"), MAX("To"), MAX(Provider), COUNT(*) AS Flags
+			FROM translationFlags
+			WHERE GuildID = $1
+				AND ($2 = '' OR LOWER(Provider) = LOWER($2))
+				AND ($3 = '' OR "From" = $3)
+				AND ($4 = '' OR "To" = $4)
+			GROUP BY GuildID, ChannelID, MessageID
+			ORDER BY Flags DESC, MessageID DESC
+			LIMIT $5
+	`, guildID, filter.Provider, filter.From, filter.To, limit)
+	if err != nil {
+		return []FlaggedTranslation{}, errors.Join(ErrInternal, err)
+	}
+	defer r.Close()
+
+	var fs []FlaggedTranslation
+	for r.Next() {
+		var f FlaggedTranslation
+
+		err = r.Scan(
+			&f.GuildID,
+			&f.ChannelID,
+			&f.MessageID,
+			&f.OriginChannelID,
+			&f.OriginID,
+			&f.From,
+			&f.To,
+			&f.Provider,
+			&f.Flags,
+		)
+		if err != nil {
+			return fs, errors.Join(ErrInternal, err)
+		}
+
+		fs = append(fs, f)
+	}
+
+	if len(fs) == 0 {
+		return fs, errors.Join(ErrNotFound, fmt.Errorf("Guild %s has no flagged translations", guildID))
+	}
+	return fs, nil
+}
+
+func (db *PostgresDB[C]) Guild(ID string) (Guild[C], error) {
+	var g struct {
+		ID     string
+		Config string
+	}
+
+	if err := db.conn().QueryRow(`
+		SELECT ID, Config FROM guilds
+			WHERE ID = $1
+	`, ID).Scan(&g.ID, &g.Config); errors.Is(err, sql.ErrNoRows) {
+		return Guild[C]{}, errors.Join(ErrNotFound, err)
+	} else if err != nil {
+		return Guild[C]{}, errors.Join(ErrInternal, err)
+	}
+
+	var c C
+	err := json.Unmarshal([]byte(g.Config), &c)
+	if err != nil {
+		return Guild[C]{}, errors.Join(ErrConfigParsing, err)
+	}
+
+	return Guild[C]{g.ID, c}, nil
+}
+
+func (db *PostgresDB[C]) GuildInsert(g Guild[C]) error {
+	j, err := marshalConfig(g.Config)
+	if err != nil {
+		return err
+	}
+
+	r, err := db.conn().Exec(`
+		INSERT INTO guilds (ID, Config)
+			VALUES ($1, $2)
+			ON CONFLICT DO NOTHING
+	`, g.ID, string(j))
+
+	if err != nil {
+		return errors.Join(ErrInternal, err)
+	} else if rows, _ := r.RowsAffected(); rows == 0 {
+		return ErrNoAffect
+	}
+
+	return nil
+}
+
+func (db *PostgresDB[C]) GuildUpdate(g Guild[C]) error {
+	j, err := marshalConfig(g.Config)
+	if err != nil {
+		return err
+	}
+
+	r, err := db.conn().Exec(`
+		UPDATE guilds
+			SET Config = $1
+			WHERE ID = $2
+	`, string(j), g.ID)
+
+	if err != nil {
+		return errors.Join(ErrInternal, err)
+	} else if rows, _ := r.RowsAffected(); rows == 0 {
+		return ErrNoAffect
+	}
+
+	return nil
+}
+
+func (db *PostgresDB[C]) GuildDelete(g Guild[C]) error {
+	return withTx(db.sql, db.tx, func(tx *sql.Tx) error {
+		if err := checkGuildUnreferenced(tx, g.ID); err != nil {
+			return err
+		}
+
+		r, err := tx.Exec(`
+			DELETE FROM guilds
+				WHERE ID = $1
+		`, g.ID)
+
+		if err != nil {
+			return errors.Join(ErrInternal, err)
+		} else if rows, _ := r.RowsAffected(); rows == 0 {
+			return ErrNoAffect
+		}
+
+		return nil
+	})
+}
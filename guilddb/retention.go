@@ -0,0 +1,73 @@
+package guilddb
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Stores a time.Time as milliseconds since the Unix epoch, so it can be
+// compared and ordered in both SQLite and PostgreSQL.
+type unixMilli time.Time
+
+func (t unixMilli) Value() (driver.Value, error) {
+	return time.Time(t).UnixMilli(), nil
+}
+
+func (t *unixMilli) Scan(src any) error {
+	ms, ok := src.(int64)
+	if !ok {
+		return fmt.Errorf("Cannot scan %T into a timestamp", src)
+	}
+	*t = unixMilli(time.UnixMilli(ms))
+	return nil
+}
+
+// Deletes up to limit original messages of the guild created before the
+// provided time, and their translations, used by both SQLiteDB and PostgresDB
+// inside a transaction.
+func pruneMessages(tx *sql.Tx, guildID string, before time.Time, limit int) (int, error) {
+	r, err := tx.Query(`
+		SELECT ChannelID, ID FROM messages
+			WHERE GuildID = $1 AND OriginID IS NULL AND Timestamp < $2
+			ORDER BY Timestamp
+			LIMIT $3
+	`, guildID, unixMilli(before), limit)
+	if err != nil {
+		return 0, errors.Join(ErrInternal, err)
+	}
+	defer r.Close()
+
+	var keys [][2]string
+	for r.Next() {
+		var k [2]string
+		if err := r.Scan(&k[0], &k[1]); err != nil {
+			return 0, errors.Join(ErrInternal, err)
+		}
+		keys = append(keys, k)
+	}
+	if err := r.Err(); err != nil {
+		return 0, errors.Join(ErrInternal, err)
+	}
+	r.Close()
+
+	for _, k := range keys {
+		if _, err := tx.Exec(`
+			DELETE FROM messages
+				WHERE GuildID = $1 AND OriginChannelID = $2 AND OriginID = $3
+		`, guildID, k[0], k[1]); err != nil {
+			return 0, errors.Join(ErrInternal, err)
+		}
+
+		if _, err := tx.Exec(`
+			DELETE FROM messages
+				WHERE GuildID = $1 AND ChannelID = $2 AND ID = $3
+		`, guildID, k[0], k[1]); err != nil {
+			return 0, errors.Join(ErrInternal, err)
+		}
+	}
+
+	return len(keys), nil
+}
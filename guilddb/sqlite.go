@@ -6,7 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"slices"
-	"strings"
+	"time"
 
 	"forge.capytal.company/capytal/dislate/translator"
 
@@ -15,6 +15,8 @@ import (
 
 type SQLiteDB[C any] struct {
 	sql *sql.DB
+	// Transaction of a database scoped by Tx, nil otherwise.
+	tx *sql.Tx
 }
 
 func NewSQLiteDB[C any](file string) (*SQLiteDB[C], error) {
@@ -23,48 +25,73 @@ func NewSQLiteDB[C any](file string) (*SQLiteDB[C], error) {
 		return &SQLiteDB[C]{}, err
 	}
 	db.SetMaxOpenConns(1)
-	return &SQLiteDB[C]{db}, nil
+	return &SQLiteDB[C]{db, nil}, nil
 }
 
 func (db *SQLiteDB[C]) Close() error {
 	return db.sql.Close()
 }
 
+func (db *SQLiteDB[C]) Tx(f func(tx GuildDB[C]) error) error {
+	return withTx(db.sql, db.tx, func(tx *sql.Tx) error {
+		return f(&SQLiteDB[C]{db.sql, tx})
+	})
+}
+
+func (db *SQLiteDB[C]) conn() querier {
+	if db.tx != nil {
+		return db.tx
+	}
+	return db.sql
+}
+
+// Migrates the database to the latest schema version.
+//
+// Will return ErrNewerSchema if the database was migrated by a newer version,
+// ErrMigrationFailed or ErrInternal.
 func (db *SQLiteDB[C]) Prepare() error {
-	if _, err := db.sql.Exec(`
+	return migrate(db.sql, "", sqliteMigrations)
+}
+
+// Returns the schema version of the database, 0 if it was never migrated.
+func (db *SQLiteDB[C]) SchemaVersion() (int, error) {
+	return schemaVersion(db.sql)
+}
+
+var sqliteMigrations = []migration{
+	{1, "Initial schema", sqliteInitialSchema},
+	{2, "Normalize channel groups", sqliteNormalizeGroups},
+	{3, "Add message timestamps", sqliteMessageTimestamps},
+}
+
+// Databases created before migrations already have some of the tables, which
+// are kept, but may lack columns added afterwards.
+func sqliteInitialSchema(tx *sql.Tx) error {
+	err := execAll(tx, `
 		CREATE TABLE IF NOT EXISTS guilds (
 			ID text NOT NULL,
 			Config text NOT NULL,
 			PRIMARY KEY(ID)
 		);
-	`); err != nil {
-		return errors.Join(ErrInternal, err)
-	}
-
-	if _, err := db.sql.Exec(`
+	`, `
 		CREATE TABLE IF NOT EXISTS channels (
-			GuildID  text NOT NULL,
-			ID       text NOT NULL,
-			Language text NOT NULL,
+			GuildID           text    NOT NULL,
+			ID                text    NOT NULL,
+			Language          text    NOT NULL,
+			AutoDetect        integer NOT NULL DEFAULT 0,
+			Formality         text    NOT NULL DEFAULT '',
+			PreserveProfanity integer NOT NULL DEFAULT 0,
 			PRIMARY KEY(ID, GuildID),
 			FOREIGN KEY(GuildID) REFERENCES guilds(ID)
 		);
-	`); err != nil {
-		return errors.Join(ErrInternal, err)
-	}
-
-	if _, err := db.sql.Exec(`
+	`, `
 		CREATE TABLE IF NOT EXISTS channelGroups (
 			GuildID  text NOT NULL,
 			Channels text NOT NULL,
 			PRIMARY KEY(Channels, GuildID),
 			FOREIGN KEY(GuildID) REFERENCES guilds(ID)
 		);
-	`); err != nil {
-		return errors.Join(ErrInternal, err)
-	}
-
-	if _, err := db.sql.Exec(`
+	`, `
 		CREATE TABLE IF NOT EXISTS messages (
 			GuildID         text NOT NULL,
 			ChannelID       text NOT NULL,
@@ -72,17 +99,179 @@ func (db *SQLiteDB[C]) Prepare() error {
 			Language        text NOT NULL,
 			OriginChannelID text,
 			OriginID        text,
+			Provider        text,
 			PRIMARY KEY(ID, ChannelID, GuildID),
 			FOREIGN KEY(GuildID, ChannelID) REFERENCES channels(GuildID, ID),
 			FOREIGN KEY(GuildID, OriginChannelID, OriginID) REFERENCES messages(GuildID, ChannelID, ID)
 		);
-	`); err != nil {
-		return errors.Join(ErrInternal, err)
+	`, `
+		CREATE TABLE IF NOT EXISTS translationFlags (
+			GuildID         text NOT NULL,
+			ChannelID       text NOT NULL,
+			MessageID       text NOT NULL,
+			UserID          text NOT NULL,
+			OriginChannelID text NOT NULL,
+			OriginID        text NOT NULL,
+			"From"          text NOT NULL,
+			"To"            text NOT NULL,
+			Provider        text NOT NULL,
+			PRIMARY KEY(MessageID, ChannelID, UserID, GuildID),
+			FOREIGN KEY(GuildID) REFERENCES guilds(ID)
+		);
+	`, `
+		CREATE TABLE IF NOT EXISTS glossary (
+			GuildID     text NOT NULL,
+			Term        text NOT NULL COLLATE NOCASE,
+			Language    text NOT NULL,
+			Replacement text,
+			PRIMARY KEY(Term, Language, GuildID),
+			FOREIGN KEY(GuildID) REFERENCES guilds(ID)
+		);
+	`, `
+		CREATE TABLE IF NOT EXISTS translationCache (
+			Key         text    NOT NULL,
+			Translation text    NOT NULL,
+			CreatedAt   integer NOT NULL,
+			ExpiresAt   integer NOT NULL,
+			PRIMARY KEY(Key)
+		);
+	`)
+	if err != nil {
+		return err
+	}
+
+	columns := []struct{ table, column, definition string }{
+		{"channels", "AutoDetect", "integer NOT NULL DEFAULT 0"},
+		{"channels", "Formality", "text NOT NULL DEFAULT ''"},
+		{"channels", "PreserveProfanity", "integer NOT NULL DEFAULT 0"},
+		{"messages", "Provider", "text"},
+	}
+	for _, c := range columns {
+		var n int
+		err := tx.QueryRow(`
+			SELECT COUNT(*) FROM pragma_table_info($1)
+				WHERE "name" = $2
+		`, c.table, c.column).Scan(&n)
+		if err != nil {
+			return err
+		} else if n > 0 {
+			continue
+		}
+
+		if _, err := tx.Exec(
+			fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s %s`, c.table, c.column, c.definition),
+		); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
+// Replaces channelGroups, which stored the IDs of the Channels in a JSON array,
+// with a groups table and a group_members table with a row for each Channel.
+func sqliteNormalizeGroups(tx *sql.Tx) error {
+	err := execAll(tx, `
+		CREATE TABLE groups (
+			ID      integer NOT NULL,
+			GuildID text    NOT NULL,
+			PRIMARY KEY(ID),
+			FOREIGN KEY(GuildID) REFERENCES guilds(ID)
+		);
+	`, `
+		CREATE TABLE group_members (
+			GuildID   text    NOT NULL,
+			ChannelID text    NOT NULL,
+			GroupID   integer NOT NULL,
+			PRIMARY KEY(ChannelID, GuildID),
+			FOREIGN KEY(GroupID) REFERENCES groups(ID),
+			FOREIGN KEY(GuildID, ChannelID) REFERENCES channels(GuildID, ID)
+		);
+	`, `
+		CREATE INDEX group_members_GroupID ON group_members (GroupID);
+	`)
+	if err != nil {
+		return err
+	}
+
+	r, err := tx.Query(`SELECT GuildID, Channels FROM channelGroups`)
+	if err != nil {
+		return err
+	}
+
+	var gs []ChannelGroup
+	for r.Next() {
+		var guildID, j string
+		if err := r.Scan(&guildID, &j); err != nil {
+			r.Close()
+			return err
+		}
+
+		var ids []string
+		if err := json.Unmarshal([]byte(j), &ids); err != nil {
+			r.Close()
+			return errors.Join(fmt.Errorf("Invalid channel group %q", j), err)
+		}
+
+		g := make(ChannelGroup, len(ids))
+		for i, id := range ids {
+			g[i] = Channel{GuildID: guildID, ID: id}
+		}
+		gs = append(gs, g)
+	}
+	r.Close()
+	if err := r.Err(); err != nil {
+		return err
+	}
+
+	// Older versions didn't stop Channels from being in many groups, only the
+	// first one is kept, and groups left with a single Channel are dropped.
+	seen := make(map[Channel]bool)
+	for _, g := range gs {
+		var cs ChannelGroup
+		for _, c := range g {
+			if !seen[c] {
+				seen[c] = true
+				cs = append(cs, c)
+			}
+		}
+		if len(cs) < 2 {
+			continue
+		}
+
+		var id int64
+		err := tx.QueryRow(`
+			INSERT INTO groups (GuildID)
+				VALUES ($1)
+				RETURNING ID
+		`, cs[0].GuildID).Scan(&id)
+		if err != nil {
+			return err
+		}
+
+		for _, c := range cs {
+			if err := insertGroupMember(tx, id, c); err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err = tx.Exec(`DROP TABLE channelGroups`)
+	return err
+}
+
+// Existing messages are timestamped with the time of the migration, so they
+// are only pruned after a full retention period.
+func sqliteMessageTimestamps(tx *sql.Tx) error {
+	return execAll(tx, `
+		ALTER TABLE messages ADD COLUMN Timestamp integer NOT NULL DEFAULT 0;
+	`, `
+		UPDATE messages SET Timestamp = CAST(strftime('%s', 'now') AS integer) * 1000;
+	`, `
+		CREATE INDEX messages_Timestamp ON messages (GuildID, Timestamp);
+	`)
+}
+
 func (db *SQLiteDB[C]) Message(guildID, channelID, messageID string) (Message, error) {
 	return db.selectMessage(`
 		WHERE "GuildID" = $1 AND "ChannelID" = $2 AND "ID" = $3
@@ -121,10 +310,20 @@ func (db *SQLiteDB[C]) MessageInsert(m Message) error {
 		)
 	}
 
-	r, err := db.sql.Exec(`
-		INSERT OR IGNORE INTO messages (GuildID, ChannelID, ID, Language, OriginChannelID, OriginID)
-			VALUES ($1, $2, $3, $4, $5, $6)
-	`, m.GuildID, m.ChannelID, m.ID, m.Language, m.OriginChannelID, m.OriginID)
+	r, err := db.conn().Exec(`
+		INSERT OR IGNORE INTO messages
+			(GuildID, ChannelID, ID, Language, OriginChannelID, OriginID, Provider, Timestamp)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`,
+		m.GuildID,
+		m.ChannelID,
+		m.ID,
+		m.Language,
+		m.OriginChannelID,
+		m.OriginID,
+		m.Provider,
+		unixMilli(m.Timestamp),
+	)
 
 	if err != nil {
 		return errors.Join(ErrInternal, err)
@@ -136,13 +335,14 @@ func (db *SQLiteDB[C]) MessageInsert(m Message) error {
 }
 
 func (db *SQLiteDB[C]) MessageUpdate(m Message) error {
-	r, err := db.sql.Exec(`
+	r, err := db.conn().Exec(`
 		UPDATE messages
-			SET Language = $1, OriginChannelID = $2, OriginID = $3
-			WHERE "GuildID" = $4 AND "ChannelID" = $5 AND "ID" = $6
+			SET Language = $1, OriginChannelID = $2, OriginID = $3, Provider = $4
+			WHERE "GuildID" = $5 AND "ChannelID" = $6 AND "ID" = $7
 	`, m.Language,
 		m.OriginChannelID,
 		m.OriginID,
+		m.Provider,
 		m.GuildID,
 		m.ChannelID,
 		m.ID,
@@ -158,7 +358,7 @@ func (db *SQLiteDB[C]) MessageUpdate(m Message) error {
 }
 
 func (db *SQLiteDB[C]) MessageDelete(m Message) error {
-	_, err := db.sql.Exec(`
+	_, err := db.conn().Exec(`
 		DELETE FROM messages
 			WHERE "GuildID" = $1 AND "OriginChannelID" = $2 AND "OriginID" = $3
 	`, m.GuildID, m.ChannelID, m.ID)
@@ -166,7 +366,7 @@ func (db *SQLiteDB[C]) MessageDelete(m Message) error {
 		return errors.Join(ErrInternal, err)
 	}
 
-	r, err := db.sql.Exec(`
+	r, err := db.conn().Exec(`
 		DELETE FROM messages
 			WHERE "GuildID" = $1 AND "ChannelID" = $2 AND "ID" = $3
 	`, m.GuildID, m.ChannelID, m.ID)
@@ -181,26 +381,63 @@ func (db *SQLiteDB[C]) MessageDelete(m Message) error {
 }
 
 func (db *SQLiteDB[C]) MessageDeleteFromChannel(c Channel) error {
-	r, err := db.sql.Exec(`
-		DELETE FROM messages
-			WHERE "GuildID" = $1 AND "ChannelID" = $2
-	`, c.GuildID, c.ID)
-	if err != nil && !errors.Is(err, ErrNoAffect) {
-		return errors.Join(ErrInternal, err)
-	} else if rows, _ := r.RowsAffected(); rows == 0 {
-		return ErrNoAffect
-	}
+	return withTx(db.sql, db.tx, func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`
+			DELETE FROM messages
+				WHERE "GuildID" = $1 AND "OriginChannelID" = $2
+		`, c.GuildID, c.ID); err != nil {
+			return errors.Join(ErrInternal, err)
+		}
 
-	return nil
+		r, err := tx.Exec(`
+			DELETE FROM messages
+				WHERE "GuildID" = $1 AND "ChannelID" = $2
+		`, c.GuildID, c.ID)
+
+		if err != nil {
+			return errors.Join(ErrInternal, err)
+		} else if rows, _ := r.RowsAffected(); rows == 0 {
+			return ErrNoAffect
+		}
+
+		return nil
+	})
+}
+
+func (db *SQLiteDB[C]) Messages(guildID string) ([]Message, error) {
+	return db.selectMessages(`
+		WHERE "GuildID" = $1
+		ORDER BY "OriginID" IS NOT NULL, "Timestamp", "ChannelID", "ID"
+	`, guildID)
+}
+
+func (db *SQLiteDB[C]) MessagesPrune(guildID string, before time.Time, limit int) (int, error) {
+	var n int
+	err := withTx(db.sql, db.tx, func(tx *sql.Tx) error {
+		var err error
+		n, err = pruneMessages(tx, guildID, before, limit)
+		return err
+	})
+	return n, err
 }
 
 func (db *SQLiteDB[C]) selectMessage(query string, args ...any) (Message, error) {
 	var m Message
-	err := db.sql.QueryRow(fmt.Sprintf(`
-		SELECT GuildID, ChannelID, ID, Language, OriginChannelID, OriginID FROM messages
+	err := db.conn().QueryRow(fmt.Sprintf(`
+		SELECT GuildID, ChannelID, ID, Language, OriginChannelID, OriginID, Provider, Timestamp
+			FROM messages
 			%s
 	`, query), args...).
-		Scan(&m.GuildID, &m.ChannelID, &m.ID, &m.Language, &m.OriginChannelID, &m.OriginID)
+		Scan(
+			&m.GuildID,
+			&m.ChannelID,
+			&m.ID,
+			&m.Language,
+			&m.OriginChannelID,
+			&m.OriginID,
+			&m.Provider,
+			(*unixMilli)(&m.Timestamp),
+		)
 
 	if errors.Is(err, sql.ErrNoRows) {
 		return m, errors.Join(ErrNotFound, err)
@@ -212,21 +449,30 @@ func (db *SQLiteDB[C]) selectMessage(query string, args ...any) (Message, error)
 }
 
 func (db *SQLiteDB[C]) selectMessages(query string, args ...any) ([]Message, error) {
-	r, err := db.sql.Query(fmt.Sprintf(`
-		SELECT GuildID, ChannelID, ID, Language, OriginChannelID, OriginID FROM messages
+	r, err := db.conn().Query(fmt.Sprintf(`
+		SELECT GuildID, ChannelID, ID, Language, OriginChannelID, OriginID, Provider, Timestamp
+			FROM messages
 			%s
 	`, query), args...)
-	defer r.Close()
-
 	if err != nil {
 		return []Message{}, errors.Join(ErrInternal, err)
 	}
+	defer r.Close()
 
 	var ms []Message
 	for r.Next() {
 		var m Message
 
-		err = r.Scan(&m.GuildID, &m.ChannelID, &m.ID, &m.Language, &m.OriginChannelID, &m.OriginID)
+		err = r.Scan(
+			&m.GuildID,
+			&m.ChannelID,
+			&m.ID,
+			&m.Language,
+			&m.OriginChannelID,
+			&m.OriginID,
+			&m.Provider,
+			(*unixMilli)(&m.Timestamp),
+		)
 		if err != nil {
 			return ms, errors.Join(
 				ErrInternal,
@@ -253,11 +499,29 @@ func (db *SQLiteDB[C]) Channel(guildID, ID string) (Channel, error) {
 	`, guildID, ID)
 }
 
+func (db *SQLiteDB[C]) Channels(guildID string) ([]Channel, error) {
+	return db.selectChannels(`
+		WHERE "GuildID" = $1
+		ORDER BY "ID"
+	`, guildID)
+}
+
 func (db *SQLiteDB[C]) ChannelInsert(c Channel) error {
-	r, err := db.sql.Exec(`
-		INSERT OR IGNORE INTO channels (GuildID, ID, Language)
-			VALUES ($1, $2, $3)
-	`, c.GuildID, c.ID, c.Language)
+	if err := checkGuildExists(db.conn(), c.GuildID); err != nil {
+		return err
+	}
+
+	r, err := db.conn().Exec(`
+		INSERT OR IGNORE INTO channels
+			(GuildID, ID, Language, AutoDetect, Formality, PreserveProfanity)
+			VALUES ($1, $2, $3, $4, $5, $6)
+	`, c.GuildID,
+		c.ID,
+		c.Language,
+		c.AutoDetect,
+		c.Options.Formality,
+		c.Options.PreserveProfanity,
+	)
 
 	if err != nil {
 		return errors.Join(ErrInternal, err)
@@ -269,11 +533,17 @@ func (db *SQLiteDB[C]) ChannelInsert(c Channel) error {
 }
 
 func (db *SQLiteDB[C]) ChannelUpdate(c Channel) error {
-	r, err := db.sql.Exec(`
+	r, err := db.conn().Exec(`
 		UPDATE channels
-			SET Language = $1
-			WHERE "GuildID" = $2 AND "ID" = $3
-	`, c.Language, c.GuildID, c.ID)
+			SET Language = $1, AutoDetect = $2, Formality = $3, PreserveProfanity = $4
+			WHERE "GuildID" = $5 AND "ID" = $6
+	`, c.Language,
+		c.AutoDetect,
+		c.Options.Formality,
+		c.Options.PreserveProfanity,
+		c.GuildID,
+		c.ID,
+	)
 
 	if err != nil {
 		return errors.Join(ErrInternal, err)
@@ -285,158 +555,168 @@ func (db *SQLiteDB[C]) ChannelUpdate(c Channel) error {
 }
 
 func (db *SQLiteDB[C]) ChannelDelete(c Channel) error {
-	r, err := db.sql.Exec(`
-		DELETE FROM channels
-			WHERE "GuildID" = $1 AND "ID" = $2
-	`, c.ID, c.ID)
+	return withTx(db.sql, db.tx, func(tx *sql.Tx) error {
+		if err := checkChannelUnreferenced(tx, c); err != nil {
+			return err
+		}
 
-	if err != nil {
-		return errors.Join(ErrInternal, err)
-	} else if rows, _ := r.RowsAffected(); rows == 0 {
-		return ErrNoAffect
-	}
+		r, err := tx.Exec(`
+			DELETE FROM channels
+				WHERE "GuildID" = $1 AND "ID" = $2
+		`, c.GuildID, c.ID)
 
-	return nil
+		if err != nil {
+			return errors.Join(ErrInternal, err)
+		} else if rows, _ := r.RowsAffected(); rows == 0 {
+			return ErrNoAffect
+		}
+
+		return nil
+	})
 }
 
 func (db *SQLiteDB[C]) ChannelGroup(guildID, channelID string) (ChannelGroup, error) {
-	var j string
-	err := db.sql.QueryRow(fmt.Sprintf(`
-		SELECT Channels FROM channelGroups, json_each(Channels)
-			WHERE "GuildID" = $1 AND json_each.value='%s';
-	`, channelID), guildID).Scan(&j)
-
-	if errors.Is(err, sql.ErrNoRows) {
-		return ChannelGroup{}, errors.Join(ErrNotFound, err)
-	} else if err != nil {
-		return ChannelGroup{}, errors.Join(ErrInternal, err)
-	}
-
-	var ids []string
-	err = json.Unmarshal([]byte(j), &ids)
-	if err != nil {
-		return ChannelGroup{}, errors.Join(ErrInternal, err)
-	}
-	for i, v := range ids {
-		ids[i] = fmt.Sprintf("\"ID\" = %s", v)
-	}
-
-	cs, err := db.selectChannels(fmt.Sprintf(`
-		WHERE %s AND "GuildID" = $1
-	`, strings.Join(ids, " OR ")), guildID)
-
-	if errors.Is(err, ErrNotFound) || len(cs) != len(ids) {
-		return ChannelGroup{}, errors.Join(
-			ErrPreconditionFailed,
-			fmt.Errorf(
-				"ChannelGroup has Channels that doesn't exist in the database, group: %s",
-				ids,
-			),
-			err,
+	cs, err := db.selectChannels(`
+		WHERE "GuildID" = $1 AND "ID" IN (
+			SELECT "ChannelID" FROM group_members
+				WHERE "GroupID" = (
+					SELECT "GroupID" FROM group_members
+						WHERE "GuildID" = $1 AND "ChannelID" = $2
+				)
 		)
-	} else if err != nil {
-		return ChannelGroup{}, errors.Join(ErrInternal, err)
+		ORDER BY "ID"
+	`, guildID, channelID)
+	if err != nil {
+		return ChannelGroup{}, err
 	}
 
 	return cs, nil
 }
 
+func (db *SQLiteDB[C]) ChannelGroups(guildID string) ([]ChannelGroup, error) {
+	return selectGroups(db.conn(), guildID)
+}
+
 func (db *SQLiteDB[C]) ChannelGroupInsert(g ChannelGroup) error {
 	if len(g) == 0 {
 		return ErrNoAffect
 	}
 
-	var ids []string
-	for _, c := range g {
-		ids = append(ids, c.ID)
-	}
-	slices.Sort(ids)
+	return withTx(db.sql, db.tx, func(tx *sql.Tx) error {
+		var id int64
+		err := tx.QueryRow(`
+			INSERT INTO groups (GuildID)
+				VALUES ($1)
+				RETURNING ID
+		`, g[0].GuildID).Scan(&id)
+		if err != nil {
+			return errors.Join(ErrInternal, err)
+		}
 
-	j, err := json.Marshal(ids)
-	if err != nil {
-		return errors.Join(ErrInternal, err)
-	}
+		for _, c := range g {
+			if err := insertGroupMember(tx, id, c); err != nil {
+				return err
+			}
+		}
 
-	r, err := db.sql.Exec(fmt.Sprintf(`
-		INSERT OR IGNORE INTO channelGroups (GuildID, Channels)
-			VALUES ($1, json('%s'))
-	`, string(j)), g[0].GuildID)
+		return nil
+	})
+}
 
-	if err != nil {
-		return errors.Join(ErrInternal, err)
-	} else if rows, _ := r.RowsAffected(); rows == 0 {
-		return ErrNoAffect
-	}
+func (db *SQLiteDB[C]) ChannelGroupAdd(member, c Channel) error {
+	return withTx(db.sql, db.tx, func(tx *sql.Tx) error {
+		id, err := selectGroupID(tx, member)
+		if err != nil {
+			return err
+		}
 
-	return nil
+		return insertGroupMember(tx, id, c)
+	})
 }
 
-func (db *SQLiteDB[C]) ChannelGroupUpdate(g ChannelGroup) error {
-	if len(g) != 0 {
-		return nil
-	}
+func (db *SQLiteDB[C]) ChannelGroupRemove(c Channel) error {
+	return withTx(db.sql, db.tx, func(tx *sql.Tx) error {
+		var id int64
+		err := tx.QueryRow(`
+			DELETE FROM group_members
+				WHERE "GuildID" = $1 AND "ChannelID" = $2
+				RETURNING GroupID
+		`, c.GuildID, c.ID).Scan(&id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNoAffect
+		} else if err != nil {
+			return errors.Join(ErrInternal, err)
+		}
 
-	var ids, idsq []string
-	for _, c := range g {
-		ids = append(ids, c.ID)
-		idsq = append(idsq, "json_each.value='"+c.ID+"'")
-	}
-	slices.Sort(ids)
+		var n int
+		err = tx.QueryRow(`
+			SELECT COUNT(*) FROM group_members
+				WHERE "GroupID" = $1
+		`, id).Scan(&n)
+		if err != nil {
+			return errors.Join(ErrInternal, err)
+		}
 
-	r, err := db.sql.Exec(
-		fmt.Sprintf(`
-			UPDATE channelGroups, json_each(Channels)
-				SET Channels = $1
-				WHERE %s AND "GuildID" = $2
-		`, strings.Join(idsq, " OR ")),
-		strings.Join(ids, ","),
-		g[0].GuildID,
-	)
+		// A single Channel has nothing to be translated to
+		if n < 2 {
+			if err := deleteGroup(tx, id); err != nil {
+				return err
+			}
+		}
 
-	if err != nil {
-		return errors.Join(ErrInternal, err)
-	} else if rows, _ := r.RowsAffected(); rows == 0 {
-		return ErrNoAffect
-	}
+		return nil
+	})
+}
 
-	return nil
+func (db *SQLiteDB[C]) ChannelGroupMerge(member, c Channel) error {
+	return withTx(db.sql, db.tx, func(tx *sql.Tx) error {
+		return mergeGroups(tx, member, c)
+	})
 }
 
 func (db *SQLiteDB[C]) ChannelGroupDelete(g ChannelGroup) error {
-	if len(g) != 0 {
-		return nil
-	}
-
-	var ids, idsq []string
-	for _, c := range g {
-		ids = append(ids, c.ID)
-		idsq = append(idsq, "json_each.value='"+c.ID+"'")
-	}
-	slices.Sort(ids)
+	return withTx(db.sql, db.tx, func(tx *sql.Tx) error {
+		var ids []int64
+		for _, c := range g {
+			id, err := selectGroupID(tx, c)
+			if errors.Is(err, ErrNotFound) {
+				continue
+			} else if err != nil {
+				return err
+			}
+
+			if !slices.Contains(ids, id) {
+				ids = append(ids, id)
+			}
+		}
 
-	r, err := db.sql.Exec(
-		fmt.Sprintf(`
-			DELETE FROM channelGroups, json_each(Channels)
-				WHERE %s AND "GuildID" = $2
-		`, strings.Join(idsq, " OR ")),
-		g[0].GuildID,
-	)
+		if len(ids) == 0 {
+			return ErrNoAffect
+		}
 
-	if err != nil {
-		return errors.Join(ErrInternal, err)
-	} else if rows, _ := r.RowsAffected(); rows == 0 {
-		return ErrNoAffect
-	}
+		for _, id := range ids {
+			if err := deleteGroup(tx, id); err != nil {
+				return err
+			}
+		}
 
-	return nil
+		return nil
+	})
 }
 
 func (db *SQLiteDB[C]) selectChannel(query string, args ...any) (Channel, error) {
 	var c Channel
-	err := db.sql.QueryRow(fmt.Sprintf(`
-		SELECT GuildID, ID, Language FROM channels
+	err := db.conn().QueryRow(fmt.Sprintf(`
+		SELECT GuildID, ID, Language, AutoDetect, Formality, PreserveProfanity FROM channels
 			%s
-	`, query), args...).Scan(&c.GuildID, &c.ID, &c.Language)
+	`, query), args...).Scan(
+		&c.GuildID,
+		&c.ID,
+		&c.Language,
+		&c.AutoDetect,
+		&c.Options.Formality,
+		&c.Options.PreserveProfanity,
+	)
 
 	if errors.Is(err, sql.ErrNoRows) {
 		return c, errors.Join(ErrNotFound, err)
@@ -448,21 +728,27 @@ func (db *SQLiteDB[C]) selectChannel(query string, args ...any) (Channel, error)
 }
 
 func (db *SQLiteDB[C]) selectChannels(query string, args ...any) ([]Channel, error) {
-	r, err := db.sql.Query(fmt.Sprintf(`
-		SELECT GuildID, ID, Language FROM channels
+	r, err := db.conn().Query(fmt.Sprintf(`
+		SELECT GuildID, ID, Language, AutoDetect, Formality, PreserveProfanity FROM channels
 			%s
 	`, query), args...)
-	defer r.Close()
-
 	if err != nil {
 		return []Channel{}, errors.Join(ErrInternal, err)
 	}
+	defer r.Close()
 
 	var cs []Channel
 	for r.Next() {
 		var c Channel
 
-		err = r.Scan(&c.GuildID, &c.ID, &c.Language)
+		err = r.Scan(
+			&c.GuildID,
+			&c.ID,
+			&c.Language,
+			&c.AutoDetect,
+			&c.Options.Formality,
+			&c.Options.PreserveProfanity,
+		)
 		if err != nil {
 			return cs, errors.Join(
 				ErrInternal,
@@ -483,13 +769,178 @@ func (db *SQLiteDB[C]) selectChannels(query string, args ...any) ([]Channel, err
 	return cs, err
 }
 
+func (db *SQLiteDB[C]) GlossaryTerms(guildID string) ([]GlossaryTerm, error) {
+	r, err := db.conn().Query(`
+		SELECT GuildID, Term, Language, Replacement FROM glossary
+			WHERE "GuildID" = $1
+			ORDER BY "Term", "Language"
+	`, guildID)
+	if err != nil {
+		return []GlossaryTerm{}, errors.Join(ErrInternal, err)
+	}
+	defer r.Close()
+
+	var ts []GlossaryTerm
+	for r.Next() {
+		var t GlossaryTerm
+
+		err = r.Scan(&t.GuildID, &t.Term, &t.Language, &t.Replacement)
+		if err != nil {
+			return ts, errors.Join(ErrInternal, err)
+		}
+
+		ts = append(ts, t)
+	}
+
+	if len(ts) == 0 {
+		return ts, errors.Join(ErrNotFound, fmt.Errorf("Guild %s has no glossary terms", guildID))
+	}
+	return ts, nil
+}
+
+func (db *SQLiteDB[C]) GlossaryTermInsert(t GlossaryTerm) error {
+	r, err := db.conn().Exec(`
+		INSERT OR IGNORE INTO glossary (GuildID, Term, Language, Replacement)
+			VALUES ($1, $2, $3, $4)
+	`, t.GuildID, t.Term, t.Language, t.Replacement)
+
+	if err != nil {
+		return errors.Join(ErrInternal, err)
+	} else if rows, _ := r.RowsAffected(); rows == 0 {
+		return ErrNoAffect
+	}
+
+	return nil
+}
+
+func (db *SQLiteDB[C]) GlossaryTermUpdate(t GlossaryTerm) error {
+	r, err := db.conn().Exec(`
+		UPDATE glossary
+			SET Replacement = $1
+			WHERE "GuildID" = $2 AND "Term" = $3 AND "Language" = $4
+	`, t.Replacement, t.GuildID, t.Term, t.Language)
+
+	if err != nil {
+		return errors.Join(ErrInternal, err)
+	} else if rows, _ := r.RowsAffected(); rows == 0 {
+		return ErrNoAffect
+	}
+
+	return nil
+}
+
+func (db *SQLiteDB[C]) GlossaryTermDelete(t GlossaryTerm) error {
+	r, err := db.conn().Exec(`
+		DELETE FROM glossary
+			WHERE "GuildID" = $1 AND "Term" = $2 AND "Language" = $3
+	`, t.GuildID, t.Term, t.Language)
+
+	if err != nil {
+		return errors.Join(ErrInternal, err)
+	} else if rows, _ := r.RowsAffected(); rows == 0 {
+		return ErrNoAffect
+	}
+
+	return nil
+}
+
+func (db *SQLiteDB[C]) TranslationFlagInsert(f TranslationFlag) error {
+	r, err := db.conn().Exec(`
+		INSERT OR IGNORE INTO translationFlags
+			(GuildID, ChannelID, MessageID, UserID, OriginChannelID, OriginID, "From", "To", Provider)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, f.GuildID,
+		f.ChannelID,
+		f.MessageID,
+		f.UserID,
+		f.OriginChannelID,
+		f.OriginID,
+		f.From,
+		f.To,
+		f.Provider,
+	)
+
+	if err != nil {
+		return errors.Join(ErrInternal, err)
+	} else if rows, _ := r.RowsAffected(); rows == 0 {
+		return ErrNoAffect
+	}
+
+	return nil
+}
+
+func (db *SQLiteDB[C]) TranslationFlagDelete(f TranslationFlag) error {
+	r, err := db.conn().Exec(`
+		DELETE FROM translationFlags
+			WHERE "GuildID" = $1 AND "ChannelID" = $2 AND "MessageID" = $3 AND "UserID" = $4
+	`, f.GuildID, f.ChannelID, f.MessageID, f.UserID)
+
+	if err != nil {
+		return errors.Join(ErrInternal, err)
+	} else if rows, _ := r.RowsAffected(); rows == 0 {
+		return ErrNoAffect
+	}
+
+	return nil
+}
+
+func (db *SQLiteDB[C]) FlaggedTranslations(
+	guildID string,
+	filter FlagFilter,
+	limit int,
+) ([]FlaggedTranslation, error) {
+	r, err := db.conn().Query(`
+		SELECT GuildID, ChannelID, MessageID, OriginChannelID, OriginID,
+			"From", "To", Provider, COUNT(*) AS Flags
+			FROM translationFlags
+			WHERE "GuildID" = $1
+				AND ($2 = '' OR LOWER(Provider) = LOWER($2))
+				AND ($3 = '' OR "From" = $3)
+				AND ($4 = '' OR "To" = $4)
+			GROUP BY "GuildID", "ChannelID", "MessageID"
+			ORDER BY Flags DESC, "MessageID" DESC
+			LIMIT $5
+	`, guildID, filter.Provider, filter.From, filter.To, limit)
+	if err != nil {
+		return []FlaggedTranslation{}, errors.Join(ErrInternal, err)
+	}
+	defer r.Close()
+
+	var fs []FlaggedTranslation
+	for r.Next() {
+		var f FlaggedTranslation
+
+		err = r.Scan(
+			&f.GuildID,
+			&f.ChannelID,
+			&f.MessageID,
+			&f.OriginChannelID,
+			&f.OriginID,
+			&f.From,
+			&f.To,
+			&f.Provider,
+			&f.Flags,
+		)
+		if err != nil {
+			return fs, errors.Join(ErrInternal, err)
+		}
+
+		fs = append(fs, f)
+	}
+
+	if len(fs) == 0 {
+		return fs, errors.Join(ErrNotFound, fmt.Errorf("Guild %s has no flagged translations", guildID))
+	}
+	return fs, nil
+}
+
 func (db *SQLiteDB[C]) Guild(ID string) (Guild[C], error) {
 	var g struct {
 		ID     string
 		Config string
 	}
 
-	if err := db.sql.QueryRow(`
+	if err := db.conn().QueryRow(`
 		SELECT "ID", "Config" FROM guilds
 			WHERE "ID" = $1
 	`, ID).Scan(&g.ID, &g.Config); errors.Is(err, sql.ErrNoRows) {
@@ -508,12 +959,12 @@ func (db *SQLiteDB[C]) Guild(ID string) (Guild[C], error) {
 }
 
 func (db *SQLiteDB[C]) GuildInsert(g Guild[C]) error {
-	j, err := json.Marshal(g.Config)
+	j, err := marshalConfig(g.Config)
 	if err != nil {
-		return errors.Join(ErrConfigParsing, err)
+		return err
 	}
 
-	r, err := db.sql.Exec(`
+	r, err := db.conn().Exec(`
 		INSERT OR IGNORE INTO guilds (ID, Config)
 			VALUES ($1, $2)
 	`, g.ID, string(j))
@@ -528,16 +979,16 @@ func (db *SQLiteDB[C]) GuildInsert(g Guild[C]) error {
 }
 
 func (db *SQLiteDB[C]) GuildUpdate(g Guild[C]) error {
-	j, err := json.Marshal(g.Config)
+	j, err := marshalConfig(g.Config)
 	if err != nil {
-		return errors.Join(ErrConfigParsing, err)
+		return err
 	}
 
-	r, err := db.sql.Exec(fmt.Sprintf(`
+	r, err := db.conn().Exec(`
 		UPDATE guilds
-			SET "Config" = '%s'
-			WHERE "ID" = '%s'
-	`, string(j), g.ID))
+			SET "Config" = $1
+			WHERE "ID" = $2
+	`, string(j), g.ID)
 
 	if err != nil {
 		return errors.Join(ErrInternal, err)
@@ -549,16 +1000,22 @@ func (db *SQLiteDB[C]) GuildUpdate(g Guild[C]) error {
 }
 
 func (db *SQLiteDB[C]) GuildDelete(g Guild[C]) error {
-	r, err := db.sql.Exec(`
-		DELETE FROM guilds
-			WHERE "ID" = $1
-	`, g.ID)
+	return withTx(db.sql, db.tx, func(tx *sql.Tx) error {
+		if err := checkGuildUnreferenced(tx, g.ID); err != nil {
+			return err
+		}
 
-	if err != nil {
-		return errors.Join(ErrInternal, err)
-	} else if rows, _ := r.RowsAffected(); rows == 0 {
-		return ErrNoAffect
-	}
+		r, err := tx.Exec(`
+			DELETE FROM guilds
+				WHERE "ID" = $1
+		`, g.ID)
 
-	return nil
+		if err != nil {
+			return errors.Join(ErrInternal, err)
+		} else if rows, _ := r.RowsAffected(); rows == 0 {
+			return ErrNoAffect
+		}
+
+		return nil
+	})
 }
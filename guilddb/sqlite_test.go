@@ -0,0 +1,11 @@
+package guilddb_test
+
+import (
+	"testing"
+
+	"forge.capytal.company/capytal/dislate/guilddb/guilddbtest"
+)
+
+func TestSQLiteDB(t *testing.T) {
+	guilddbtest.Run(t, guilddbtest.SQLite)
+}
@@ -0,0 +1,56 @@
+-- Database created by the version of the bot before schema migrations, with
+-- channel groups stored as JSON arrays in channelGroups.
+
+CREATE TABLE guilds (
+	ID text NOT NULL,
+	Config text NOT NULL,
+	PRIMARY KEY(ID)
+);
+
+CREATE TABLE channels (
+	GuildID  text NOT NULL,
+	ID       text NOT NULL,
+	Language text NOT NULL,
+	PRIMARY KEY(ID, GuildID),
+	FOREIGN KEY(GuildID) REFERENCES guilds(ID)
+);
+
+CREATE TABLE channelGroups (
+	GuildID  text NOT NULL,
+	Channels text NOT NULL,
+	PRIMARY KEY(Channels, GuildID),
+	FOREIGN KEY(GuildID) REFERENCES guilds(ID)
+);
+
+CREATE TABLE messages (
+	GuildID         text NOT NULL,
+	ChannelID       text NOT NULL,
+	ID              text NOT NULL,
+	Language        text NOT NULL,
+	OriginChannelID text,
+	OriginID        text,
+	PRIMARY KEY(ID, ChannelID, GuildID),
+	FOREIGN KEY(GuildID, ChannelID) REFERENCES channels(GuildID, ID),
+	FOREIGN KEY(GuildID, OriginChannelID, OriginID) REFERENCES messages(GuildID, ChannelID, ID)
+);
+
+INSERT INTO guilds (ID, Config) VALUES ('100', '{"name":"Guild"}');
+
+INSERT INTO channels (GuildID, ID, Language) VALUES
+	('100', '200', 'en'),
+	('100', '201', 'pt'),
+	('100', '202', 'es'),
+	('100', '203', 'fr'),
+	('100', '204', 'de');
+
+-- Older versions let a channel be in many groups, 201 is also in the second
+-- group, which is left with a single channel after migrating.
+INSERT INTO channelGroups (GuildID, Channels) VALUES
+	('100', json('["200","201"]')),
+	('100', json('["201","202"]')),
+	('100', json('["203","204"]'));
+
+INSERT INTO messages (GuildID, ChannelID, ID, Language, OriginChannelID, OriginID) VALUES
+	('100', '200', '300', 'en', NULL, NULL),
+	('100', '201', '301', 'pt', '200', '300'),
+	('100', '203', '302', 'fr', NULL, NULL);
@@ -0,0 +1,66 @@
+package guilddb
+
+import (
+	"database/sql"
+	"errors"
+)
+
+// Methods shared by *sql.DB and *sql.Tx, so queries run in the transaction of
+// a scoped database.
+type querier interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// Runs f in a new transaction of db which is committed if f doesn't return an
+// error. If tx isn't nil, f runs in a savepoint of it instead, so only the
+// changes of f are rolled back on errors.
+//
+// Will return the error of f, or ErrInternal if the transaction couldn't be
+// started or committed.
+func withTx(db *sql.DB, tx *sql.Tx, f func(tx *sql.Tx) error) error {
+	if tx != nil {
+		return withSavepoint(tx, f)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return errors.Join(ErrInternal, err)
+	}
+	defer tx.Rollback()
+
+	if err := f(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Join(ErrInternal, err)
+	}
+
+	return nil
+}
+
+// Savepoints with the same name are nested, each ROLLBACK TO and RELEASE
+// affects the most recent one.
+func withSavepoint(tx *sql.Tx, f func(tx *sql.Tx) error) error {
+	if _, err := tx.Exec(`SAVEPOINT guilddb`); err != nil {
+		return errors.Join(ErrInternal, err)
+	}
+
+	if err := f(tx); err != nil {
+		if _, rerr := tx.Exec(`ROLLBACK TO SAVEPOINT guilddb`); rerr != nil {
+			return errors.Join(err, ErrInternal, rerr)
+		}
+		if _, rerr := tx.Exec(`RELEASE SAVEPOINT guilddb`); rerr != nil {
+			return errors.Join(err, ErrInternal, rerr)
+		}
+		return err
+	}
+
+	if _, err := tx.Exec(`RELEASE SAVEPOINT guilddb`); err != nil {
+		return errors.Join(ErrInternal, err)
+	}
+
+	return nil
+}
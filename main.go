@@ -1,10 +1,14 @@
 package main
 
 import (
+	"errors"
 	"flag"
+	"fmt"
 	"log/slog"
+	"net/url"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
@@ -20,11 +24,83 @@ type TranslationProvider string
 
 const (
 	GOOGLE_TRANSLATE TranslationProvider = "google-translate"
+	LIBRETRANSLATE   TranslationProvider = "libretranslate"
+	DEEPL            TranslationProvider = "deepl"
+	OFFLINE          TranslationProvider = "offline"
+	OFFLINE_ANNOTATE TranslationProvider = "offline-annotate"
+	MOCK             TranslationProvider = "mock"
 )
 
-// var translation_provider = flag.String("tprovider", string(GOOGLE_TRANSLATE), "Translation provider")
 var (
-	database_file = flag.String("db", "file:./guild.db", "SQLite database file/location")
+	translation_provider = flag.String(
+		"tprovider",
+		string(LIBRETRANSLATE)+","+string(GOOGLE_TRANSLATE),
+		"Comma-separated list of translation providers to try in order "+
+			"(libretranslate, google-translate, deepl, offline, offline-annotate, mock)",
+	)
+	translation_endpoint = flag.String(
+		"tendpoint",
+		"",
+		"Comma-separated list of endpoints, one for each translation provider "+
+			"(defaults to the provider's docker-compose service or public API, "+
+			"offline providers use it as the path to a CSV phrase table)",
+	)
+	translation_key = flag.String(
+		"tkey",
+		os.Getenv("TRANSLATION_API_KEY"),
+		"Comma-separated list of API keys, one for each translation provider "+
+			"(a single key is used for all providers)",
+	)
+	translation_timeout = flag.Duration(
+		"ttimeout",
+		10*time.Second,
+		"Timeout of requests to the translation provider",
+	)
+	translation_threshold = flag.Int(
+		"tthreshold",
+		3,
+		"Consecutive failures before a translation provider is temporarily skipped",
+	)
+	translation_cooldown = flag.Duration(
+		"tcooldown",
+		time.Minute,
+		"How long a failing translation provider is skipped",
+	)
+	translation_cache_ttl = flag.Duration(
+		"tcache-ttl",
+		7*24*time.Hour,
+		"How long translations are cached, 0 disables the cache",
+	)
+	translation_cache_size = flag.Int(
+		"tcache-size",
+		10000,
+		"Maximum number of cached translations",
+	)
+	translation_rate = flag.Float64(
+		"trate",
+		2,
+		"Requests per second made to each translation provider, 0 disables the limit",
+	)
+	translation_burst = flag.Int(
+		"tburst",
+		5,
+		"Requests made at once to each translation provider before being rate limited",
+	)
+	translation_workers = flag.Int(
+		"tworkers",
+		4,
+		"Maximum number of concurrent translation requests",
+	)
+	translation_queue = flag.Int(
+		"tqueue",
+		100,
+		"Maximum number of queued translation requests of each priority",
+	)
+	database_file = flag.String(
+		"db",
+		"file:./guild.db",
+		"SQLite database file/location, or PostgreSQL connection URL (postgres://...)",
+	)
 	discord_token = flag.String(
 		"token",
 		os.Getenv("DISCORD_TOKEN"),
@@ -43,19 +119,19 @@ func main() {
 		ReportCaller:    true,
 	}))
 
-	db, err := guilddb.NewSQLiteDB[gconf.ConfigString](*database_file + "?_busy_timeout=5000")
+	db, err := newDatabase(*database_file)
 	if err != nil {
 		logger.Error("Failed to open database connection", slog.String("err", err.Error()))
 		return
 	}
-	logger.Info("Connection to database started", slog.String("file", *database_file))
+	logger.Info("Connection to database started", slog.String("file", databaseName(*database_file)))
 	defer func() {
 		err := db.Close()
 		if err != nil {
 			logger.Error("Failed to close database connection", slog.String("err", err.Error()))
 			return
 		}
-		logger.Info("Connection to database closed", slog.String("file", *database_file))
+		logger.Info("Connection to database closed", slog.String("file", databaseName(*database_file)))
 	}()
 
 	if err := db.Prepare(); err != nil {
@@ -64,7 +140,82 @@ func main() {
 	}
 	logger.Info("Database ready to be used")
 
-	bot, err := bot.NewBot(*discord_token, db, translator.NewMockTranslator(), logger)
+	if flag.NArg() > 0 {
+		if err := runCommand(db, flag.Args()); err != nil {
+			logger.Error("Failed to run command",
+				slog.String("command", flag.Arg(0)),
+				slog.String("err", err.Error()),
+			)
+			return
+		}
+		logger.Info("Command finished", slog.String("command", flag.Arg(0)))
+		return
+	}
+
+	ps, err := newTranslationProviders()
+	if err != nil {
+		logger.Error("Failed to create translator", slog.String("err", err.Error()))
+		return
+	}
+	for i, p := range ps {
+		logger.Info("Translation provider created", slog.String("provider", p.Name))
+
+		if *translation_rate > 0 {
+			r := translator.NewRateLimit(p.Translator, *translation_rate, *translation_burst)
+			ps[i].Translator = r
+			defer func() {
+				st := r.Stats()
+				logger.Info("Translation rate limit statistics",
+					slog.String("provider", p.Name),
+					slog.Uint64("requests", st.Requests),
+					slog.Uint64("throttled", st.Throttled),
+					slog.Duration("waited", st.Waited),
+				)
+			}()
+		}
+
+		if *translation_cache_ttl <= 0 {
+			continue
+		}
+
+		c := translator.NewCache(
+			ps[i].Translator,
+			db,
+			p.Name,
+			*translation_cache_ttl,
+			*translation_cache_size,
+		)
+		ps[i].Translator = c
+		defer func() {
+			st := c.Stats()
+			logger.Info("Translation cache statistics",
+				slog.String("provider", p.Name),
+				slog.Uint64("hits", st.Hits),
+				slog.Uint64("misses", st.Misses),
+				slog.Uint64("errors", st.Errors),
+			)
+		}()
+	}
+	t := translator.NewQueue(
+		translator.NewMarkdown(
+			translator.NewFallback(*translation_threshold, *translation_cooldown, ps...),
+		),
+		*translation_workers,
+		*translation_queue,
+	)
+	defer func() {
+		t.Close()
+		st := t.Stats()
+		logger.Info("Translation queue statistics",
+			slog.Int("depth", st.Depth),
+			slog.Uint64("processed", st.Processed),
+			slog.Uint64("rejected", st.Rejected),
+			slog.Duration("average_wait", st.AverageWait),
+			slog.Duration("max_wait", st.MaxWait),
+		)
+	}()
+
+	bot, err := bot.NewBot(*discord_token, db, t, logger)
 	if err != nil {
 		logger.Error("Failed to create discord bot", slog.String("err", err.Error()))
 		return
@@ -86,3 +237,100 @@ func main() {
 	signal.Notify(sig, os.Interrupt, syscall.SIGINT)
 	<-sig
 }
+
+type database interface {
+	gconf.DB
+	translator.CacheStore
+	Prepare() error
+	Close() error
+}
+
+// Opens a PostgreSQL database if the location is a connection URL, or a SQLite
+// database otherwise.
+func newDatabase(location string) (database, error) {
+	if guilddb.IsPostgresDSN(location) {
+		return guilddb.NewPostgresDB[gconf.ConfigString](location)
+	}
+	return guilddb.NewSQLiteDB[gconf.ConfigString](location + "?_busy_timeout=5000")
+}
+
+// Returns the location without the password of PostgreSQL connection URLs, so
+// it can be logged.
+func databaseName(location string) string {
+	if u, err := url.Parse(location); err == nil && guilddb.IsPostgresDSN(location) {
+		return u.Redacted()
+	}
+	return location
+}
+
+func newTranslationProviders() ([]translator.FallbackProvider, error) {
+	names := strings.Split(*translation_provider, ",")
+	endpoints := strings.Split(*translation_endpoint, ",")
+	keys := strings.Split(*translation_key, ",")
+
+	ps := make([]translator.FallbackProvider, len(names))
+	for i, n := range names {
+		var endpoint string
+		if i < len(endpoints) {
+			endpoint = strings.TrimSpace(endpoints[i])
+		}
+		key := strings.TrimSpace(keys[0])
+		if i < len(keys) {
+			key = strings.TrimSpace(keys[i])
+		}
+
+		p := TranslationProvider(strings.TrimSpace(n))
+		t, err := newTranslator(p, endpoint, key)
+		if err != nil {
+			return nil, err
+		}
+		ps[i] = translator.FallbackProvider{Name: string(p), Translator: t}
+	}
+
+	return ps, nil
+}
+
+func newTranslator(
+	p TranslationProvider,
+	endpoint, key string,
+) (translator.Translator, error) {
+	switch p {
+	case LIBRETRANSLATE:
+		if endpoint == "" {
+			endpoint = "http://localhost:5000"
+		}
+		return translator.NewLibreTranslate(endpoint, key, *translation_timeout), nil
+	case GOOGLE_TRANSLATE:
+		if endpoint == "" {
+			endpoint = "http://localhost:8999"
+		}
+		return translator.NewTranslateer(endpoint, *translation_timeout), nil
+	case DEEPL:
+		if endpoint == "" {
+			endpoint = "https://api-free.deepl.com"
+		}
+		return translator.NewDeepL(endpoint, key, *translation_timeout), nil
+	case OFFLINE, OFFLINE_ANNOTATE:
+		phrases := translator.DefaultPhraseTable()
+		if endpoint != "" {
+			f, err := os.Open(endpoint)
+			if err != nil {
+				return nil, errors.Join(errors.New("Failed to open phrase table"), err)
+			}
+			defer f.Close()
+
+			if phrases, err = translator.ParsePhraseTable(f); err != nil {
+				return nil, err
+			}
+		}
+		return translator.NewOffline(
+			phrases,
+			translator.DefaultTrigramDetector(),
+			p == OFFLINE_ANNOTATE,
+		), nil
+	case MOCK:
+		return translator.NewMockTranslator(), nil
+	default:
+		return nil, fmt.Errorf("Unsupported translation provider %q", p)
+	}
+}
@@ -0,0 +1,81 @@
+package translator
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Translations of a batch of texts to a single language.
+type BatchResult struct {
+	Texts []string
+	// Name of the provider which translated the texts, if known.
+	Provider string
+}
+
+// Translators able to translate many texts to many languages with fewer
+// requests to the provider.
+type BatchTranslator interface {
+	TranslateBatch(
+		ctx context.Context,
+		from Language,
+		to []Language,
+		texts []string,
+		opts Options,
+	) (map[Language]BatchResult, error)
+}
+
+// Translates all texts to all languages, using t's TranslateBatch if it is a
+// BatchTranslator. Otherwise each language is translated concurrently, one text
+// at a time.
+func TranslateBatch(
+	ctx context.Context,
+	t Translator,
+	from Language,
+	to []Language,
+	texts []string,
+	opts Options,
+) (map[Language]BatchResult, error) {
+	if bt, ok := t.(BatchTranslator); ok {
+		return bt.TranslateBatch(ctx, from, to, texts, opts)
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	res := make(map[Language]BatchResult, len(to))
+	errs := make([]error, 0)
+
+	for _, l := range to {
+		wg.Add(1)
+		go func(l Language) {
+			defer wg.Done()
+
+			r := BatchResult{Texts: make([]string, len(texts))}
+			for i, text := range texts {
+				var err error
+				if pt, ok := t.(ProviderTranslator); ok {
+					r.Texts[i], r.Provider, err = pt.TranslateWithProvider(ctx, from, l, text, opts)
+				} else {
+					r.Texts[i], err = t.Translate(ctx, from, l, text, opts)
+				}
+				if err != nil {
+					mu.Lock()
+					errs = append(errs, err)
+					mu.Unlock()
+					return
+				}
+			}
+
+			mu.Lock()
+			res[l] = r
+			mu.Unlock()
+		}(l)
+	}
+
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return res, errors.Join(errs...)
+	}
+	return res, nil
+}
@@ -0,0 +1,209 @@
+package translator
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Storage used by Cache to persist translations.
+type CacheStore interface {
+	// Returns the translation stored with the key, ok is false if there isn't
+	// one or if it already expired.
+	CacheGet(key string) (translation string, ok bool, err error)
+	// Stores a translation with the key, replacing any existing one.
+	CacheSet(key, translation string, expires time.Time) error
+	// Removes expired translations and the oldest ones until at most max
+	// translations are stored.
+	CacheTrim(max int) error
+}
+
+type CacheStats struct {
+	Hits   uint64
+	Misses uint64
+	Errors uint64
+}
+
+// Cache decorates a Translator, serving repeated translations from a CacheStore
+// instead of calling the provider. Failures of the store are counted in the
+// stats but never fail the translation.
+type Cache struct {
+	translator Translator
+	store      CacheStore
+	provider   string
+	ttl        time.Duration
+	max        int
+
+	sets   atomic.Uint64
+	hits   atomic.Uint64
+	misses atomic.Uint64
+	errors atomic.Uint64
+}
+
+// How many translations are stored between each trim of the CacheStore.
+const cacheTrimInterval = 100
+
+func NewCache(t Translator, store CacheStore, provider string, ttl time.Duration, max int) *Cache {
+	return &Cache{translator: t, store: store, provider: provider, ttl: ttl, max: max}
+}
+
+func (c *Cache) Translate(
+	ctx context.Context,
+	from, to Language,
+	text string,
+	opts Options,
+) (string, error) {
+	if from == to || text == "" {
+		return text, nil
+	}
+
+	key := c.key(from, to, text, opts)
+	if r, ok := c.get(key); ok {
+		return r, nil
+	}
+
+	r, err := c.translator.Translate(ctx, from, to, text, opts)
+	if err != nil {
+		return r, err
+	}
+	c.set(key, r)
+
+	return r, nil
+}
+
+// Serves the cached translations from the store and translates the missing
+// ones in batches, one for each set of languages missing the same texts, so
+// texts already cached for a language aren't translated to it again.
+func (c *Cache) TranslateBatch(
+	ctx context.Context,
+	from Language,
+	to []Language,
+	texts []string,
+	opts Options,
+) (map[Language]BatchResult, error) {
+	res := make(map[Language]BatchResult, len(to))
+	missing := make(map[Language][]int)
+
+	type batch struct {
+		langs     []Language
+		texts     []string
+		positions map[string]int
+	}
+	var batches []*batch
+	byTexts := make(map[string]*batch)
+
+	for _, l := range to {
+		r := BatchResult{Texts: make([]string, len(texts)), Provider: c.provider}
+		var ts []string
+		positions := make(map[string]int)
+		for i, text := range texts {
+			if from == l || text == "" {
+				r.Texts[i] = text
+			} else if t, ok := c.get(c.key(from, l, text, opts)); ok {
+				r.Texts[i] = t
+			} else {
+				missing[l] = append(missing[l], i)
+				if _, ok := positions[text]; !ok {
+					positions[text] = len(ts)
+					ts = append(ts, text)
+				}
+			}
+		}
+		res[l] = r
+
+		if len(ts) == 0 {
+			continue
+		}
+		k := strings.Join(ts, "\x00")
+		if b, ok := byTexts[k]; ok {
+			b.langs = append(b.langs, l)
+		} else {
+			b := &batch{[]Language{l}, ts, positions}
+			byTexts[k] = b
+			batches = append(batches, b)
+		}
+	}
+
+	for _, b := range batches {
+		br, err := TranslateBatch(ctx, c.translator, from, b.langs, b.texts, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, l := range b.langs {
+			for _, i := range missing[l] {
+				t := br[l].Texts[b.positions[texts[i]]]
+				res[l].Texts[i] = t
+				c.set(c.key(from, l, texts[i], opts), t)
+			}
+		}
+	}
+
+	return res, nil
+}
+
+func (c *Cache) Detect(ctx context.Context, text string) (Language, error) {
+	return c.translator.Detect(ctx, text)
+}
+
+func (c *Cache) DetectAll(ctx context.Context, text string) (Detection, error) {
+	return c.translator.DetectAll(ctx, text)
+}
+
+func (c *Cache) Languages(ctx context.Context) ([]Language, error) {
+	if lp, ok := c.translator.(LanguageProvider); ok {
+		return lp.Languages(ctx)
+	}
+	return LanguageCodes(), nil
+}
+
+func (c *Cache) Stats() CacheStats {
+	return CacheStats{
+		Hits:   c.hits.Load(),
+		Misses: c.misses.Load(),
+		Errors: c.errors.Load(),
+	}
+}
+
+func (c *Cache) get(key string) (string, bool) {
+	r, ok, err := c.store.CacheGet(key)
+	if err != nil {
+		c.errors.Add(1)
+		return "", false
+	} else if ok {
+		c.hits.Add(1)
+		return r, true
+	}
+
+	c.misses.Add(1)
+	return "", false
+}
+
+func (c *Cache) set(key, translation string) {
+	if err := c.store.CacheSet(key, translation, time.Now().Add(c.ttl)); err != nil {
+		c.errors.Add(1)
+	}
+	if c.sets.Add(1)%cacheTrimInterval == 0 {
+		if err := c.store.CacheTrim(c.max); err != nil {
+			c.errors.Add(1)
+		}
+	}
+}
+
+func (c *Cache) key(from, to Language, text string, opts Options) string {
+	h := sha256.New()
+	for _, s := range []string{c.provider, string(from), string(to), text} {
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+	// Translations with the default options keep the same keys they had
+	// before options existed.
+	if o := opts.String(); o != "" {
+		h.Write([]byte(o))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,179 @@
+package translator
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// A CacheStore in a map, failing every call if err isn't nil.
+type mapCacheStore struct {
+	translations map[string]string
+	err          error
+}
+
+func (s *mapCacheStore) CacheGet(key string) (string, bool, error) {
+	if s.err != nil {
+		return "", false, s.err
+	}
+	t, ok := s.translations[key]
+	return t, ok, nil
+}
+
+func (s *mapCacheStore) CacheSet(key, translation string, expires time.Time) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.translations[key] = translation
+	return nil
+}
+
+func (s *mapCacheStore) CacheTrim(max int) error {
+	return s.err
+}
+
+type recordedBatch struct {
+	to    []Language
+	texts []string
+}
+
+// Translates texts by prefixing them with the language, recording each batch.
+type batchRecorder struct {
+	batches []recordedBatch
+}
+
+func (t *batchRecorder) Translate(
+	ctx context.Context,
+	from, to Language,
+	text string,
+	opts Options,
+) (string, error) {
+	t.batches = append(t.batches, recordedBatch{[]Language{to}, []string{text}})
+	return string(to) + ":" + text, nil
+}
+
+func (t *batchRecorder) TranslateBatch(
+	ctx context.Context,
+	from Language,
+	to []Language,
+	texts []string,
+	opts Options,
+) (map[Language]BatchResult, error) {
+	t.batches = append(t.batches, recordedBatch{to, texts})
+	res := make(map[Language]BatchResult, len(to))
+	for _, l := range to {
+		r := BatchResult{Texts: make([]string, len(texts))}
+		for i, text := range texts {
+			r.Texts[i] = string(l) + ":" + text
+		}
+		res[l] = r
+	}
+	return res, nil
+}
+
+func (t *batchRecorder) Detect(ctx context.Context, text string) (Language, error) {
+	return EN, nil
+}
+
+func (t *batchRecorder) DetectAll(ctx context.Context, text string) (Detection, error) {
+	return Detection{{EN, 1}}, nil
+}
+
+func TestCacheTranslateBatch(t *testing.T) {
+	rec := &batchRecorder{}
+	store := &mapCacheStore{translations: make(map[string]string)}
+	c := NewCache(rec, store, "test", time.Hour, 100)
+	ctx := context.Background()
+
+	if _, err := c.Translate(ctx, PT, EN, "olá", Options{}); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	rec.batches = nil
+
+	texts := []string{"olá", "tchau", "olá"}
+	res, err := c.TranslateBatch(ctx, PT, []Language{EN, DE, FR}, texts, Options{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	expected := map[Language][]string{
+		EN: {"en:olá", "en:tchau", "en:olá"},
+		DE: {"de:olá", "de:tchau", "de:olá"},
+		FR: {"fr:olá", "fr:tchau", "fr:olá"},
+	}
+	for l, ts := range expected {
+		if !reflect.DeepEqual(res[l].Texts, ts) {
+			t.Fatalf("Expected %s translations %q, got %q", l, ts, res[l].Texts)
+		}
+	}
+
+	// The text cached for English isn't translated to it again
+	batches := []recordedBatch{
+		{[]Language{EN}, []string{"tchau"}},
+		{[]Language{DE, FR}, []string{"olá", "tchau"}},
+	}
+	if !reflect.DeepEqual(rec.batches, batches) {
+		t.Fatalf("Expected batches %+v, got %+v", batches, rec.batches)
+	}
+
+	rec.batches = nil
+	_, err = c.TranslateBatch(ctx, PT, []Language{EN, DE}, []string{"tchau"}, Options{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	} else if len(rec.batches) != 0 {
+		t.Fatalf("Expected all translations to be cached, got batches %+v", rec.batches)
+	}
+
+	stats := CacheStats{Hits: 4, Misses: 8}
+	if s := c.Stats(); s != stats {
+		t.Fatalf("Expected stats %+v, got %+v", stats, s)
+	}
+}
+
+func TestCacheOptions(t *testing.T) {
+	rec := &batchRecorder{}
+	store := &mapCacheStore{translations: make(map[string]string)}
+	c := NewCache(rec, store, "test", time.Hour, 100)
+	ctx := context.Background()
+
+	// Translations with other options aren't served from the cache
+	opts := []Options{{}, {Formality: FormalityFormal}, {PreserveProfanity: true}}
+	for _, o := range opts {
+		if _, err := c.Translate(ctx, PT, EN, "olá", o); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	}
+	if len(rec.batches) != len(opts) {
+		t.Fatalf("Expected a translation for each options, got %+v", rec.batches)
+	}
+
+	for _, o := range opts {
+		if _, err := c.Translate(ctx, PT, EN, "olá", o); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	}
+	if len(rec.batches) != len(opts) {
+		t.Fatalf("Expected translations to be cached, got %+v", rec.batches)
+	}
+}
+
+func TestCacheStoreErrors(t *testing.T) {
+	rec := &batchRecorder{}
+	store := &mapCacheStore{err: errors.New("Store failed")}
+	c := NewCache(rec, store, "test", time.Hour, 100)
+
+	r, err := c.Translate(context.Background(), PT, EN, "olá", Options{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	} else if r != "en:olá" {
+		t.Fatalf("Expected %q, got %q", "en:olá", r)
+	}
+
+	// Failures to get are errors, not misses
+	stats := CacheStats{Errors: 2}
+	if s := c.Stats(); s != stats {
+		t.Fatalf("Expected stats %+v, got %+v", stats, s)
+	}
+}
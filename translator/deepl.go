@@ -0,0 +1,242 @@
+package translator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DeepL requires regional variants for some target languages, while source
+// languages are always the base code.
+var deeplTargetCodes = map[Language]string{
+	EN:   "EN-US",
+	PT:   "PT-BR",
+	ZH:   "ZH-HANS",
+	ZHTW: "ZH-HANT",
+}
+
+// Preferences are used instead of the strict formality values, which DeepL
+// rejects for languages without formality support.
+var deeplFormality = map[Formality]string{
+	FormalityFormal:   "prefer_more",
+	FormalityInformal: "prefer_less",
+}
+
+// DeepL translates using the DeepL API, honoring the formality of the Options.
+// DeepL doesn't censor profanity, so it is always preserved.
+type DeepL struct {
+	endpoint string
+	apiKey   string
+	client   *http.Client
+}
+
+func NewDeepL(endpoint, apiKey string, timeout time.Duration) DeepL {
+	return DeepL{endpoint, apiKey, &http.Client{Timeout: timeout}}
+}
+
+func (t DeepL) Translate(
+	ctx context.Context,
+	from, to Language,
+	text string,
+	opts Options,
+) (string, error) {
+	if from == to || text == "" {
+		return text, nil
+	}
+
+	ts, _, err := t.translate(ctx, from, to, []string{text}, opts)
+	if err != nil {
+		return "", err
+	}
+
+	return ts[0], nil
+}
+
+// Translates all texts in a single request for each target language.
+func (t DeepL) TranslateBatch(
+	ctx context.Context,
+	from Language,
+	to []Language,
+	texts []string,
+	opts Options,
+) (map[Language]BatchResult, error) {
+	res := make(map[Language]BatchResult, len(to))
+	for _, l := range to {
+		if from == l || len(texts) == 0 {
+			res[l] = BatchResult{Texts: texts}
+			continue
+		}
+
+		ts, _, err := t.translate(ctx, from, l, texts, opts)
+		if err != nil {
+			return res, err
+		}
+		res[l] = BatchResult{Texts: ts}
+	}
+
+	return res, nil
+}
+
+func (t DeepL) Detect(ctx context.Context, text string) (Language, error) {
+	d, err := t.DetectAll(ctx, text)
+	if err != nil {
+		return "", err
+	}
+	return d[0].Language, nil
+}
+
+// DeepL only detects languages while translating and without any confidence
+// score, so the text is translated and the detection is reported as certain.
+func (t DeepL) DetectAll(ctx context.Context, text string) (Detection, error) {
+	_, detected, err := t.translate(ctx, "", EN, []string{text}, Options{})
+	if err != nil {
+		return nil, err
+	}
+
+	if detected == "" {
+		return nil, errors.Join(ErrBadResponse, errors.New("No language detected"))
+	}
+
+	return Detection{{providerLanguage(deeplTargetCodes, detected), 1}}, nil
+}
+
+func (t DeepL) Languages(ctx context.Context) ([]Language, error) {
+	var res []struct {
+		Language string `json:"language"`
+	}
+	q := url.Values{"type": {"target"}}
+	if err := t.do(ctx, http.MethodGet, "/v2/languages", q, nil, &res); err != nil {
+		return nil, err
+	}
+
+	ls := make([]Language, 0, len(res))
+	for _, l := range res {
+		if l := providerLanguage(deeplTargetCodes, l.Language); l.IsValid() {
+			ls = append(ls, l)
+		}
+	}
+
+	return ls, nil
+}
+
+// Translates the texts, an empty from makes DeepL detect the language, which is
+// returned with the translations.
+func (t DeepL) translate(
+	ctx context.Context,
+	from, to Language,
+	texts []string,
+	opts Options,
+) ([]string, string, error) {
+	body := map[string]any{
+		"text":        texts,
+		"target_lang": strings.ToUpper(providerCode(deeplTargetCodes, to)),
+	}
+	if from != "" {
+		// Source languages don't have regional variants
+		base, _, _ := strings.Cut(string(from), "-")
+		body["source_lang"] = strings.ToUpper(base)
+	}
+	if f, ok := deeplFormality[opts.Formality]; ok {
+		body["formality"] = f
+	}
+
+	var res struct {
+		Translations []struct {
+			DetectedSourceLanguage string `json:"detected_source_language"`
+			Text                   string `json:"text"`
+		} `json:"translations"`
+	}
+	if err := t.do(ctx, http.MethodPost, "/v2/translate", nil, body, &res); err != nil {
+		return nil, "", err
+	}
+
+	if len(res.Translations) != len(texts) {
+		return nil, "", errors.Join(
+			ErrBadResponse,
+			fmt.Errorf("Expected %d translations, got %d", len(texts), len(res.Translations)),
+		)
+	}
+
+	ts := make([]string, len(res.Translations))
+	for i, r := range res.Translations {
+		ts[i] = r.Text
+	}
+
+	return ts, res.Translations[0].DetectedSourceLanguage, nil
+}
+
+func (t DeepL) do(
+	ctx context.Context,
+	method, path string,
+	query url.Values,
+	body any,
+	res any,
+) error {
+	u, err := url.JoinPath(t.endpoint, path)
+	if err != nil {
+		return errors.Join(ErrBadRequest, err)
+	}
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	var r io.Reader
+	if body != nil {
+		j, err := json.Marshal(body)
+		if err != nil {
+			return errors.Join(ErrBadRequest, err)
+		}
+		r = bytes.NewReader(j)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, r)
+	if err != nil {
+		return errors.Join(ErrBadRequest, err)
+	}
+	req.Header.Set("Authorization", "DeepL-Auth-Key "+t.apiKey)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return errors.Join(ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	b, err := readResponse(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Message string `json:"message"`
+		}
+		_ = json.Unmarshal(b, &e)
+
+		// DeepL responds with 456 when the account's quota is exceeded
+		serr := statusErr(resp.StatusCode)
+		if resp.StatusCode == 456 {
+			serr = ErrUnavailable
+		}
+
+		return errors.Join(
+			serr,
+			fmt.Errorf("DeepL responded with status %d: %s", resp.StatusCode, e.Message),
+		)
+	}
+
+	if err := json.Unmarshal(b, res); err != nil {
+		return errors.Join(ErrBadResponse, err)
+	}
+
+	return nil
+}
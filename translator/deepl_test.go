@@ -0,0 +1,145 @@
+package translator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type deeplRequest struct {
+	Text       []string `json:"text"`
+	SourceLang string   `json:"source_lang"`
+	TargetLang string   `json:"target_lang"`
+	Formality  string   `json:"formality"`
+}
+
+// Starts a DeepL stand-in which prefixes texts with the target language.
+func deeplServer(t *testing.T, reqs *[]deeplRequest) string {
+	return testServer(t, "POST /v2/translate", func(w http.ResponseWriter, r *http.Request) {
+		if a := r.Header.Get("Authorization"); a != "DeepL-Auth-Key secret" {
+			respondJSON(t, w, http.StatusForbidden, map[string]string{"message": "Bad key"})
+			return
+		}
+
+		var req deeplRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("Failed to decode request: %s", err)
+		}
+		*reqs = append(*reqs, req)
+
+		type translation struct {
+			DetectedSourceLanguage string `json:"detected_source_language"`
+			Text                   string `json:"text"`
+		}
+		ts := make([]translation, len(req.Text))
+		for i, text := range req.Text {
+			ts[i] = translation{"PT", req.TargetLang + ":" + text}
+		}
+		respondJSON(t, w, http.StatusOK, map[string]any{"translations": ts})
+	})
+}
+
+func TestDeepLTranslate(t *testing.T) {
+	var reqs []deeplRequest
+	dl := NewDeepL(deeplServer(t, &reqs), "secret", time.Second)
+
+	opts := Options{Formality: FormalityFormal}
+	r, err := dl.Translate(context.Background(), ZHTW, PT, "你好", opts)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	} else if r != "PT-BR:你好" {
+		t.Fatalf("Expected translation %q, got %q", "PT-BR:你好", r)
+	}
+
+	// Source languages use the base code, targets the regional variant
+	expected := []deeplRequest{{[]string{"你好"}, "ZH", "PT-BR", "prefer_more"}}
+	if !reflect.DeepEqual(reqs, expected) {
+		t.Fatalf("Expected requests %v, got %v", expected, reqs)
+	}
+}
+
+func TestDeepLTranslateBatch(t *testing.T) {
+	var reqs []deeplRequest
+	dl := NewDeepL(deeplServer(t, &reqs), "secret", time.Second)
+
+	texts := []string{"hello", "world"}
+	res, err := dl.TranslateBatch(context.Background(), EN, []Language{EN, ES}, texts, Options{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	expected := map[Language]BatchResult{
+		EN: {Texts: texts},
+		ES: {Texts: []string{"ES:hello", "ES:world"}},
+	}
+	if !reflect.DeepEqual(res, expected) {
+		t.Fatalf("Expected %v, got %v", expected, res)
+	} else if len(reqs) != 1 {
+		t.Fatalf("Expected a single request for all texts, got %d", len(reqs))
+	}
+}
+
+func TestDeepLDetectAll(t *testing.T) {
+	var reqs []deeplRequest
+	dl := NewDeepL(deeplServer(t, &reqs), "secret", time.Second)
+
+	d, err := dl.DetectAll(context.Background(), "olá")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	} else if !reflect.DeepEqual(d, Detection{{PT, 1}}) {
+		t.Fatalf("Expected %v, got %v", Detection{{PT, 1}}, d)
+	} else if reqs[0].SourceLang != "" {
+		t.Fatalf("Expected no source language, got %q", reqs[0].SourceLang)
+	}
+}
+
+func TestDeepLStatus(t *testing.T) {
+	tests := []struct {
+		status int
+		err    error
+	}{
+		{http.StatusBadRequest, ErrBadRequest},
+		{http.StatusForbidden, ErrUnauthorized},
+		{http.StatusTooManyRequests, ErrRateLimited},
+		{456, ErrUnavailable},
+		{http.StatusServiceUnavailable, ErrUnavailable},
+	}
+
+	for _, test := range tests {
+		t.Run(http.StatusText(test.status), func(t *testing.T) {
+			url := testServer(t, "POST /v2/translate", func(w http.ResponseWriter, r *http.Request) {
+				respondJSON(t, w, test.status, map[string]string{"message": "failed"})
+			})
+
+			dl := NewDeepL(url, "secret", time.Second)
+			_, err := dl.Translate(context.Background(), EN, PT, "hello", Options{})
+			if !errors.Is(err, test.err) {
+				t.Fatalf("Expected error %q, got %v", test.err, err)
+			}
+		})
+	}
+
+	var reqs []deeplRequest
+	dl := NewDeepL(deeplServer(t, &reqs), "wrong", time.Second)
+	_, err := dl.Translate(context.Background(), EN, PT, "hello", Options{})
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("Expected error %q, got %v", ErrUnauthorized, err)
+	}
+}
+
+func TestDeepLResponseLimit(t *testing.T) {
+	url := testServer(t, "POST /v2/translate", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(strings.Repeat("a", maxResponseSize+1)))
+	})
+
+	dl := NewDeepL(url, "secret", time.Second)
+	_, err := dl.Translate(context.Background(), EN, PT, "hello", Options{})
+	if !errors.Is(err, ErrBadResponse) {
+		t.Fatalf("Expected error %q, got %v", ErrBadResponse, err)
+	}
+}
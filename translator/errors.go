@@ -0,0 +1,46 @@
+package translator
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+var (
+	ErrBadRequest   = errors.New("Invalid request to translation provider")
+	ErrUnauthorized = errors.New("Not authorized to use translation provider")
+	ErrRateLimited  = errors.New("Too many requests to translation provider")
+	ErrUnavailable  = errors.New("Translation provider is unavailable")
+	ErrBadResponse  = errors.New("Invalid response from translation provider")
+)
+
+func statusErr(status int) error {
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return ErrUnauthorized
+	case status == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case status >= 500:
+		return ErrUnavailable
+	default:
+		return ErrBadRequest
+	}
+}
+
+// Responses of providers bigger than this are rejected, so a misbehaving
+// endpoint can't make the bot use unbounded memory.
+const maxResponseSize = 4 << 20
+
+func readResponse(r io.Reader) ([]byte, error) {
+	b, err := io.ReadAll(io.LimitReader(r, maxResponseSize+1))
+	if err != nil {
+		return nil, errors.Join(ErrBadResponse, err)
+	} else if len(b) > maxResponseSize {
+		return nil, errors.Join(
+			ErrBadResponse,
+			fmt.Errorf("Response is bigger than the limit of %d bytes", maxResponseSize),
+		)
+	}
+	return b, nil
+}
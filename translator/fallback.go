@@ -0,0 +1,223 @@
+package translator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+var ErrNoProvider = errors.New("No translation provider available")
+
+// Translators that delegate the work to other providers can report which one
+// produced the result.
+type ProviderTranslator interface {
+	Translator
+	TranslateWithProvider(
+		ctx context.Context,
+		from, to Language,
+		text string,
+		opts Options,
+	) (string, string, error)
+}
+
+type FallbackProvider struct {
+	Name       string
+	Translator Translator
+}
+
+// Fallback tries each provider in order until one of them succeeds. Providers
+// that fail threshold times in a row are skipped until cooldown has passed,
+// after which a single request is let through to check if they recovered.
+type Fallback struct {
+	providers []*breaker
+}
+
+func NewFallback(threshold int, cooldown time.Duration, ps ...FallbackProvider) *Fallback {
+	bs := make([]*breaker, len(ps))
+	for i, p := range ps {
+		bs[i] = &breaker{
+			name:       p.Name,
+			translator: p.Translator,
+			threshold:  threshold,
+			cooldown:   cooldown,
+		}
+	}
+	return &Fallback{bs}
+}
+
+func (t *Fallback) Translate(
+	ctx context.Context,
+	from, to Language,
+	text string,
+	opts Options,
+) (string, error) {
+	r, _, err := t.TranslateWithProvider(ctx, from, to, text, opts)
+	return r, err
+}
+
+func (t *Fallback) TranslateWithProvider(
+	ctx context.Context,
+	from, to Language,
+	text string,
+	opts Options,
+) (string, string, error) {
+	var r string
+	p, err := t.try(ctx, func(tr Translator) error {
+		var err error
+		r, err = tr.Translate(ctx, from, to, text, opts)
+		return err
+	})
+	return r, p, err
+}
+
+func (t *Fallback) TranslateBatch(
+	ctx context.Context,
+	from Language,
+	to []Language,
+	texts []string,
+	opts Options,
+) (map[Language]BatchResult, error) {
+	var res map[Language]BatchResult
+	p, err := t.try(ctx, func(tr Translator) error {
+		var err error
+		res, err = TranslateBatch(ctx, tr, from, to, texts, opts)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for l, r := range res {
+		r.Provider = p
+		res[l] = r
+	}
+	return res, nil
+}
+
+func (t *Fallback) Detect(ctx context.Context, text string) (Language, error) {
+	var l Language
+	_, err := t.try(ctx, func(tr Translator) error {
+		var err error
+		l, err = tr.Detect(ctx, text)
+		return err
+	})
+	return l, err
+}
+
+func (t *Fallback) DetectAll(ctx context.Context, text string) (Detection, error) {
+	var d Detection
+	_, err := t.try(ctx, func(tr Translator) error {
+		var err error
+		d, err = tr.DetectAll(ctx, text)
+		return err
+	})
+	return d, err
+}
+
+// Returns the languages supported by at least one of the providers. Providers
+// which don't implement LanguageProvider are assumed to support the whole registry.
+func (t *Fallback) Languages(ctx context.Context) ([]Language, error) {
+	var ls []Language
+	for _, b := range t.providers {
+		lp, ok := b.translator.(LanguageProvider)
+		if !ok {
+			return LanguageCodes(), nil
+		}
+
+		pls, err := lp.Languages(ctx)
+		if err != nil {
+			continue
+		}
+		for _, l := range pls {
+			if !slices.Contains(ls, l) {
+				ls = append(ls, l)
+			}
+		}
+	}
+
+	if len(ls) == 0 {
+		return nil, ErrNoProvider
+	}
+	return ls, nil
+}
+
+// Stops trying other providers once the context is done, in that case the
+// failure isn't held against the provider.
+func (t *Fallback) try(ctx context.Context, f func(Translator) error) (string, error) {
+	errs := []error{ErrNoProvider}
+	for _, b := range t.providers {
+		if !b.allow() {
+			continue
+		}
+
+		err := f(b.translator)
+		if err != nil && ctx.Err() != nil {
+			b.release()
+			return "", errors.Join(append(errs, ctx.Err())...)
+		}
+		b.report(err)
+		if err == nil {
+			return b.name, nil
+		}
+
+		errs = append(errs, fmt.Errorf("Provider %s failed: %w", b.name, err))
+	}
+	return "", errors.Join(errs...)
+}
+
+type breaker struct {
+	name       string
+	translator Translator
+	threshold  int
+	cooldown   time.Duration
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+func (b *breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold {
+		return true
+	}
+	if time.Now().Before(b.openUntil) || b.probing {
+		return false
+	}
+
+	b.probing = true
+	return true
+}
+
+// Lets another request probe the provider, without counting the current one as
+// a success or failure.
+func (b *breaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+func (b *breaker) report(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+
+	// Bad requests are caused by the input, not by the provider's health.
+	if err == nil || errors.Is(err, ErrBadRequest) {
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openUntil = time.Now().Add(b.cooldown)
+	}
+}
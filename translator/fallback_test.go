@@ -0,0 +1,128 @@
+package translator
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// Returns err, or the text prefixed with its name, counting the calls made to it.
+type stubTranslator struct {
+	name  string
+	err   error
+	calls int
+}
+
+func (t *stubTranslator) Translate(
+	ctx context.Context,
+	from, to Language,
+	text string,
+	opts Options,
+) (string, error) {
+	t.calls++
+	if t.err != nil {
+		return "", t.err
+	}
+	return t.name + ":" + text, nil
+}
+
+func (t *stubTranslator) Detect(ctx context.Context, text string) (Language, error) {
+	return EN, nil
+}
+
+func (t *stubTranslator) DetectAll(ctx context.Context, text string) (Detection, error) {
+	return Detection{{EN, 1}}, nil
+}
+
+func TestFallbackBreaker(t *testing.T) {
+	const cooldown = 50 * time.Millisecond
+
+	primary := &stubTranslator{name: "primary", err: ErrUnavailable}
+	secondary := &stubTranslator{name: "secondary"}
+	f := NewFallback(2, cooldown,
+		FallbackProvider{"primary", primary},
+		FallbackProvider{"secondary", secondary},
+	)
+
+	translate := func(expectedProvider string, expectedCalls int) {
+		t.Helper()
+		r, p, err := f.TranslateWithProvider(context.Background(), EN, PT, "hi", Options{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		} else if p != expectedProvider || r != expectedProvider+":hi" {
+			t.Fatalf("Expected translation of %s, got %q from %s", expectedProvider, r, p)
+		} else if primary.calls != expectedCalls {
+			t.Fatalf("Expected %d calls to primary, got %d", expectedCalls, primary.calls)
+		}
+	}
+
+	// The breaker opens after two failures in a row
+	translate("secondary", 1)
+	translate("secondary", 2)
+	translate("secondary", 2)
+
+	// A single request probes the provider after the cooldown, and failing
+	// opens the breaker again
+	time.Sleep(cooldown)
+	translate("secondary", 3)
+	translate("secondary", 3)
+
+	time.Sleep(cooldown)
+	primary.err = nil
+	translate("primary", 4)
+	translate("primary", 5)
+
+	// Bad requests are caused by the input, so the provider keeps being tried
+	primary.err = ErrBadRequest
+	for i := 6; i < 10; i++ {
+		translate("secondary", i)
+	}
+}
+
+func TestFallbackNoProvider(t *testing.T) {
+	primary := &stubTranslator{name: "primary", err: ErrUnavailable}
+	secondary := &stubTranslator{name: "secondary", err: ErrRateLimited}
+	f := NewFallback(1, time.Minute,
+		FallbackProvider{"primary", primary},
+		FallbackProvider{"secondary", secondary},
+	)
+
+	_, err := f.Translate(context.Background(), EN, PT, "hi", Options{})
+	if !errors.Is(err, ErrNoProvider) || !errors.Is(err, ErrUnavailable) ||
+		!errors.Is(err, ErrRateLimited) {
+		t.Fatalf("Expected errors of all providers, got %v", err)
+	}
+
+	// Both breakers are open, so no provider is tried
+	_, err = f.Translate(context.Background(), EN, PT, "hi", Options{})
+	if !errors.Is(err, ErrNoProvider) {
+		t.Fatalf("Expected error %q, got %v", ErrNoProvider, err)
+	} else if primary.calls != 1 || secondary.calls != 1 {
+		t.Fatalf("Expected open breakers to skip providers, got %d and %d calls",
+			primary.calls, secondary.calls)
+	}
+}
+
+func TestFallbackCancelled(t *testing.T) {
+	primary := &stubTranslator{name: "primary", err: context.Canceled}
+	secondary := &stubTranslator{name: "secondary"}
+	f := NewFallback(1, time.Minute,
+		FallbackProvider{"primary", primary},
+		FallbackProvider{"secondary", secondary},
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Failures of cancelled requests aren't held against the provider, and no
+	// other provider is tried
+	for i := 1; i <= 2; i++ {
+		if _, err := f.Translate(ctx, EN, PT, "hi", Options{}); !errors.Is(err, context.Canceled) {
+			t.Fatalf("Expected error %q, got %v", context.Canceled, err)
+		} else if primary.calls != i || secondary.calls != 0 {
+			t.Fatalf("Expected %d calls to primary only, got %d and %d",
+				i, primary.calls, secondary.calls)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package translator
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type GlossaryEntry struct {
+	Term string
+	// Target language of the replacement, empty applies to all languages.
+	Language Language
+	// Text used in place of the term, nil keeps the term as is.
+	Replacement *string
+}
+
+var glossaryPlaceholder = regexp.MustCompile(`⟦g(\d+)⟧`)
+
+// Glossary decorates a Translator, replacing the glossary's terms with
+// placeholders before translating and with their replacements afterwards.
+type Glossary struct {
+	translator Translator
+	entries    []GlossaryEntry
+	terms      *regexp.Regexp
+}
+
+func NewGlossary(t Translator, entries []GlossaryEntry) Glossary {
+	if len(entries) == 0 {
+		return Glossary{t, entries, nil}
+	}
+
+	ts := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if e.Term != "" && !slices.Contains(ts, regexp.QuoteMeta(e.Term)) {
+			ts = append(ts, regexp.QuoteMeta(e.Term))
+		}
+	}
+	// Longer terms first, so "Dislate Bot" has priority over "Dislate".
+	slices.SortFunc(ts, func(a, b string) int { return len(b) - len(a) })
+
+	return Glossary{t, entries, regexp.MustCompile("(?i)" + strings.Join(ts, "|"))}
+}
+
+func (t Glossary) Translate(
+	ctx context.Context,
+	from, to Language,
+	text string,
+	opts Options,
+) (string, error) {
+	s, terms := t.replaceTerms(text)
+
+	r, err := t.translator.Translate(ctx, from, to, s, opts)
+	if err != nil {
+		return "", err
+	}
+
+	return t.restoreTerms(to, r, terms), nil
+}
+
+func (t Glossary) TranslateWithProvider(
+	ctx context.Context,
+	from, to Language,
+	text string,
+	opts Options,
+) (string, string, error) {
+	pt, ok := t.translator.(ProviderTranslator)
+	if !ok {
+		r, err := t.Translate(ctx, from, to, text, opts)
+		return r, "", err
+	}
+
+	s, terms := t.replaceTerms(text)
+
+	r, p, err := pt.TranslateWithProvider(ctx, from, to, s, opts)
+	if err != nil {
+		return "", p, err
+	}
+
+	return t.restoreTerms(to, r, terms), p, nil
+}
+
+func (t Glossary) TranslateBatch(
+	ctx context.Context,
+	from Language,
+	to []Language,
+	texts []string,
+	opts Options,
+) (map[Language]BatchResult, error) {
+	ss := make([]string, len(texts))
+	terms := make([][]string, len(texts))
+	for i, text := range texts {
+		ss[i], terms[i] = t.replaceTerms(text)
+	}
+
+	res, err := TranslateBatch(ctx, t.translator, from, to, ss, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	for l, r := range res {
+		for i, text := range r.Texts {
+			r.Texts[i] = t.restoreTerms(l, text, terms[i])
+		}
+		res[l] = r
+	}
+
+	return res, nil
+}
+
+func (t Glossary) Detect(ctx context.Context, text string) (Language, error) {
+	return t.translator.Detect(ctx, text)
+}
+
+func (t Glossary) DetectAll(ctx context.Context, text string) (Detection, error) {
+	return t.translator.DetectAll(ctx, text)
+}
+
+func (t Glossary) Languages(ctx context.Context) ([]Language, error) {
+	if lp, ok := t.translator.(LanguageProvider); ok {
+		return lp.Languages(ctx)
+	}
+	return LanguageCodes(), nil
+}
+
+func (t Glossary) replaceTerms(text string) (string, []string) {
+	if t.terms == nil {
+		return text, nil
+	}
+
+	var terms []string
+	var r strings.Builder
+	i := 0
+	for _, m := range t.terms.FindAllStringIndex(text, -1) {
+		// Only whole words are glossary terms
+		before, _ := utf8.DecodeLastRuneInString(text[:m[0]])
+		after, _ := utf8.DecodeRuneInString(text[m[1]:])
+		if isWordRune(before) || isWordRune(after) {
+			continue
+		}
+
+		r.WriteString(text[i:m[0]])
+		r.WriteString(fmt.Sprintf("⟦g%d⟧", len(terms)))
+		terms = append(terms, text[m[0]:m[1]])
+		i = m[1]
+	}
+	r.WriteString(text[i:])
+
+	return r.String(), terms
+}
+
+func (t Glossary) restoreTerms(to Language, text string, terms []string) string {
+	return glossaryPlaceholder.ReplaceAllStringFunc(text, func(p string) string {
+		i, err := strconv.Atoi(glossaryPlaceholder.FindStringSubmatch(p)[1])
+		if err != nil || i >= len(terms) {
+			return p
+		}
+		return t.replacement(to, terms[i])
+	})
+}
+
+func (t Glossary) replacement(to Language, term string) string {
+	var entry *GlossaryEntry
+	for i, e := range t.entries {
+		if !strings.EqualFold(e.Term, term) {
+			continue
+		}
+		if e.Language == to {
+			entry = &t.entries[i]
+			break
+		} else if e.Language == "" && entry == nil {
+			entry = &t.entries[i]
+		}
+	}
+
+	// Terms without an entry for the target language are kept as is.
+	if entry == nil || entry.Replacement == nil {
+		return term
+	}
+	return *entry.Replacement
+}
+
+func isWordRune(r rune) bool {
+	return r != utf8.RuneError && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
@@ -1,8 +1,236 @@
 package translator
 
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Language is a ISO 639-1 code, optionally followed by a BCP-47 region subtag
+// where the regional variant is a distinct written language (e.g. zh-TW).
 type Language string
 
 const (
-	EN Language = "en"
-	PT Language = "pt"
+	AR   Language = "ar"
+	AZ   Language = "az"
+	BG   Language = "bg"
+	BN   Language = "bn"
+	CA   Language = "ca"
+	CS   Language = "cs"
+	DA   Language = "da"
+	DE   Language = "de"
+	EL   Language = "el"
+	EN   Language = "en"
+	EO   Language = "eo"
+	ES   Language = "es"
+	ET   Language = "et"
+	FA   Language = "fa"
+	FI   Language = "fi"
+	FR   Language = "fr"
+	GA   Language = "ga"
+	HE   Language = "he"
+	HI   Language = "hi"
+	HU   Language = "hu"
+	ID   Language = "id"
+	IT   Language = "it"
+	JA   Language = "ja"
+	KO   Language = "ko"
+	LT   Language = "lt"
+	LV   Language = "lv"
+	MS   Language = "ms"
+	NB   Language = "nb"
+	NL   Language = "nl"
+	PL   Language = "pl"
+	PT   Language = "pt"
+	RO   Language = "ro"
+	RU   Language = "ru"
+	SK   Language = "sk"
+	SL   Language = "sl"
+	SQ   Language = "sq"
+	SV   Language = "sv"
+	TH   Language = "th"
+	TL   Language = "tl"
+	TR   Language = "tr"
+	UK   Language = "uk"
+	UR   Language = "ur"
+	VI   Language = "vi"
+	ZH   Language = "zh"
+	ZHTW Language = "zh-TW"
 )
+
+var ErrUnknownLanguage = errors.New("Unknown language")
+
+type LanguageInfo struct {
+	Code       Language
+	Name       string
+	NativeName string
+}
+
+var languages = []LanguageInfo{
+	{AR, "Arabic", "العربية"},
+	{AZ, "Azerbaijani", "Azərbaycan dili"},
+	{BG, "Bulgarian", "Български"},
+	{BN, "Bengali", "বাংলা"},
+	{CA, "Catalan", "Català"},
+	{CS, "Czech", "Čeština"},
+	{DA, "Danish", "Dansk"},
+	{DE, "German", "Deutsch"},
+	{EL, "Greek", "Ελληνικά"},
+	{EN, "English", "English"},
+	{EO, "Esperanto", "Esperanto"},
+	{ES, "Spanish", "Español"},
+	{ET, "Estonian", "Eesti"},
+	{FA, "Persian", "فارسی"},
+	{FI, "Finnish", "Suomi"},
+	{FR, "French", "Français"},
+	{GA, "Irish", "Gaeilge"},
+	{HE, "Hebrew", "עברית"},
+	{HI, "Hindi", "हिन्दी"},
+	{HU, "Hungarian", "Magyar"},
+	{ID, "Indonesian", "Bahasa Indonesia"},
+	{IT, "Italian", "Italiano"},
+	{JA, "Japanese", "日本語"},
+	{KO, "Korean", "한국어"},
+	{LT, "Lithuanian", "Lietuvių"},
+	{LV, "Latvian", "Latviešu"},
+	{MS, "Malay", "Bahasa Melayu"},
+	{NB, "Norwegian Bokmål", "Norsk bokmål"},
+	{NL, "Dutch", "Nederlands"},
+	{PL, "Polish", "Polski"},
+	{PT, "Portuguese", "Português"},
+	{RO, "Romanian", "Română"},
+	{RU, "Russian", "Русский"},
+	{SK, "Slovak", "Slovenčina"},
+	{SL, "Slovenian", "Slovenščina"},
+	{SQ, "Albanian", "Shqip"},
+	{SV, "Swedish", "Svenska"},
+	{TH, "Thai", "ไทย"},
+	{TL, "Tagalog", "Tagalog"},
+	{TR, "Turkish", "Türkçe"},
+	{UK, "Ukrainian", "Українська"},
+	{UR, "Urdu", "اردو"},
+	{VI, "Vietnamese", "Tiếng Việt"},
+	{ZH, "Chinese (Simplified)", "简体中文"},
+	{ZHTW, "Chinese (Traditional)", "繁體中文"},
+}
+
+// Translators which only support a subset of the registry can advertise which
+// languages they are able to translate from and to.
+type LanguageProvider interface {
+	Languages(ctx context.Context) ([]Language, error)
+}
+
+// Returns all languages in the registry.
+func Languages() []LanguageInfo {
+	return slices.Clone(languages)
+}
+
+// Returns all language codes in the registry.
+func LanguageCodes() []Language {
+	ls := make([]Language, len(languages))
+	for i, l := range languages {
+		ls[i] = l.Code
+	}
+	return ls
+}
+
+// Parses a language code or name into a Language in the registry. Codes are
+// case-insensitive and may use "_" as separator; unknown region subtags fall
+// back to their base language (e.g. "pt-BR" is parsed as PT).
+//
+// Will return ErrUnknownLanguage if no language is found.
+func ParseLanguage(s string) (Language, error) {
+	s = strings.TrimSpace(s)
+	code := strings.ReplaceAll(s, "_", "-")
+
+	for _, l := range languages {
+		if strings.EqualFold(string(l.Code), code) ||
+			strings.EqualFold(l.Name, s) ||
+			strings.EqualFold(l.NativeName, s) {
+			return l.Code, nil
+		}
+	}
+
+	if base, _, ok := strings.Cut(code, "-"); ok {
+		if l, err := ParseLanguage(base); err == nil {
+			return l, nil
+		}
+	}
+
+	return "", errors.Join(ErrUnknownLanguage, fmt.Errorf("Language %q is not supported", s))
+}
+
+// Returns languages whose code, name or native name contains the query,
+// ordered with prefix matches first.
+func SearchLanguages(query string) []LanguageInfo {
+	q := strings.ToLower(strings.TrimSpace(query))
+
+	var prefix, contains []LanguageInfo
+	for _, l := range languages {
+		fs := []string{
+			strings.ToLower(string(l.Code)),
+			strings.ToLower(l.Name),
+			strings.ToLower(l.NativeName),
+		}
+		if slices.ContainsFunc(fs, func(f string) bool { return strings.HasPrefix(f, q) }) {
+			prefix = append(prefix, l)
+		} else if slices.ContainsFunc(fs, func(f string) bool { return strings.Contains(f, q) }) {
+			contains = append(contains, l)
+		}
+	}
+
+	return append(prefix, contains...)
+}
+
+func (l Language) IsValid() bool {
+	return slices.ContainsFunc(languages, func(i LanguageInfo) bool { return i.Code == l })
+}
+
+func (l Language) Info() (LanguageInfo, bool) {
+	i := slices.IndexFunc(languages, func(i LanguageInfo) bool { return i.Code == l })
+	if i == -1 {
+		return LanguageInfo{Code: l, Name: string(l), NativeName: string(l)}, false
+	}
+	return languages[i], true
+}
+
+func (l Language) Name() string {
+	i, _ := l.Info()
+	return i.Name
+}
+
+func (l Language) NativeName() string {
+	i, _ := l.Info()
+	return i.NativeName
+}
+
+func (i LanguageInfo) String() string {
+	if i.Name == i.NativeName {
+		return fmt.Sprintf("%s (%s)", i.Name, i.Code)
+	}
+	return fmt.Sprintf("%s / %s (%s)", i.Name, i.NativeName, i.Code)
+}
+
+// Maps a Language to the code used by a provider, codes not in the map are
+// used as is.
+func providerCode(codes map[Language]string, l Language) string {
+	if c, ok := codes[l]; ok {
+		return c
+	}
+	return string(l)
+}
+
+// Maps a provider's code back to a Language, the inverse of providerCode.
+func providerLanguage(codes map[Language]string, code string) Language {
+	for l, c := range codes {
+		if strings.EqualFold(c, code) {
+			return l
+		}
+	}
+	if l, err := ParseLanguage(code); err == nil {
+		return l
+	}
+	return Language(code)
+}
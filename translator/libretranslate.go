@@ -0,0 +1,223 @@
+package translator
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+)
+
+var libreTranslateCodes = map[Language]string{
+	ZHTW: "zt",
+}
+
+type LibreTranslate struct {
+	endpoint string
+	apiKey   string
+	client   *http.Client
+}
+
+func NewLibreTranslate(endpoint, apiKey string, timeout time.Duration) LibreTranslate {
+	return LibreTranslate{endpoint, apiKey, &http.Client{Timeout: timeout}}
+}
+
+func (t LibreTranslate) Translate(
+	ctx context.Context,
+	from, to Language,
+	text string,
+	opts Options,
+) (string, error) {
+	if from == to || text == "" {
+		return text, nil
+	}
+
+	var res struct {
+		TranslatedText string `json:"translatedText"`
+	}
+	err := t.post(ctx, "/translate", map[string]string{
+		"q":       text,
+		"source":  providerCode(libreTranslateCodes, from),
+		"target":  providerCode(libreTranslateCodes, to),
+		"format":  "text",
+		"api_key": t.apiKey,
+	}, &res)
+	if err != nil {
+		return "", err
+	}
+
+	return res.TranslatedText, nil
+}
+
+// Translates all texts in a single request for each target language.
+func (t LibreTranslate) TranslateBatch(
+	ctx context.Context,
+	from Language,
+	to []Language,
+	texts []string,
+	opts Options,
+) (map[Language]BatchResult, error) {
+	res := make(map[Language]BatchResult, len(to))
+	for _, l := range to {
+		if from == l || len(texts) == 0 {
+			res[l] = BatchResult{Texts: texts}
+			continue
+		}
+
+		var r struct {
+			TranslatedText []string `json:"translatedText"`
+		}
+		err := t.post(ctx, "/translate", map[string]any{
+			"q":       texts,
+			"source":  providerCode(libreTranslateCodes, from),
+			"target":  providerCode(libreTranslateCodes, l),
+			"format":  "text",
+			"api_key": t.apiKey,
+		}, &r)
+		if err != nil {
+			return res, err
+		} else if len(r.TranslatedText) != len(texts) {
+			return res, errors.Join(
+				ErrBadResponse,
+				fmt.Errorf("Expected %d translations, got %d", len(texts), len(r.TranslatedText)),
+			)
+		}
+
+		res[l] = BatchResult{Texts: r.TranslatedText}
+	}
+
+	return res, nil
+}
+
+func (t LibreTranslate) Detect(ctx context.Context, text string) (Language, error) {
+	d, err := t.DetectAll(ctx, text)
+	if err != nil {
+		return "", err
+	}
+	return d[0].Language, nil
+}
+
+func (t LibreTranslate) DetectAll(ctx context.Context, text string) (Detection, error) {
+	var res []struct {
+		Confidence float64 `json:"confidence"`
+		Language   string  `json:"language"`
+	}
+	err := t.post(ctx, "/detect", map[string]string{
+		"q":       text,
+		"api_key": t.apiKey,
+	}, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(res) == 0 {
+		return nil, errors.Join(ErrBadResponse, errors.New("No language detected"))
+	}
+
+	d := make(Detection, len(res))
+	for i, r := range res {
+		// LibreTranslate's confidence goes from 0 to 100
+		d[i] = DetectedLanguage{
+			Language:   providerLanguage(libreTranslateCodes, r.Language),
+			Confidence: r.Confidence / 100,
+		}
+	}
+	slices.SortStableFunc(d, func(a, b DetectedLanguage) int {
+		return cmp.Compare(b.Confidence, a.Confidence)
+	})
+
+	return d, nil
+}
+
+func (t LibreTranslate) Languages(ctx context.Context) ([]Language, error) {
+	u, err := url.JoinPath(t.endpoint, "/languages")
+	if err != nil {
+		return nil, errors.Join(ErrBadRequest, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, errors.Join(ErrBadRequest, err)
+	}
+
+	r, err := t.client.Do(req)
+	if err != nil {
+		return nil, errors.Join(ErrUnavailable, err)
+	}
+	defer r.Body.Close()
+
+	if r.StatusCode != http.StatusOK {
+		return nil, errors.Join(
+			statusErr(r.StatusCode),
+			fmt.Errorf("LibreTranslate responded with status %d", r.StatusCode),
+		)
+	}
+
+	var res []struct {
+		Code string `json:"code"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&res); err != nil {
+		return nil, errors.Join(ErrBadResponse, err)
+	}
+
+	ls := make([]Language, 0, len(res))
+	for _, l := range res {
+		if l := providerLanguage(libreTranslateCodes, l.Code); l.IsValid() {
+			ls = append(ls, l)
+		}
+	}
+
+	return ls, nil
+}
+
+func (t LibreTranslate) post(ctx context.Context, path string, body any, res any) error {
+	u, err := url.JoinPath(t.endpoint, path)
+	if err != nil {
+		return errors.Join(ErrBadRequest, err)
+	}
+
+	j, err := json.Marshal(body)
+	if err != nil {
+		return errors.Join(ErrBadRequest, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(j))
+	if err != nil {
+		return errors.Join(ErrBadRequest, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	r, err := t.client.Do(req)
+	if err != nil {
+		return errors.Join(ErrUnavailable, err)
+	}
+	defer r.Body.Close()
+
+	b, err := readResponse(r.Body)
+	if err != nil {
+		return err
+	}
+
+	if r.StatusCode != http.StatusOK {
+		var e struct {
+			Error string `json:"error"`
+		}
+		_ = json.Unmarshal(b, &e)
+
+		return errors.Join(
+			statusErr(r.StatusCode),
+			fmt.Errorf("LibreTranslate responded with status %d: %s", r.StatusCode, e.Error),
+		)
+	}
+
+	if err := json.Unmarshal(b, res); err != nil {
+		return errors.Join(ErrBadResponse, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,193 @@
+package translator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Starts a server which handles path with f, closed when the test ends.
+func testServer(t *testing.T, path string, f http.HandlerFunc) string {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc(path, f)
+	s := httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s.URL
+}
+
+func respondJSON(t *testing.T, w http.ResponseWriter, status int, v any) {
+	t.Helper()
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		t.Errorf("Failed to encode response: %s", err)
+	}
+}
+
+func TestLibreTranslateTranslate(t *testing.T) {
+	var body map[string]any
+	url := testServer(t, "POST /translate", func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("Failed to decode request: %s", err)
+		}
+		respondJSON(t, w, http.StatusOK, map[string]string{"translatedText": "olá"})
+	})
+
+	lt := NewLibreTranslate(url, "secret", time.Second)
+	r, err := lt.Translate(context.Background(), EN, ZHTW, "hello", Options{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	} else if r != "olá" {
+		t.Fatalf("Expected translation %q, got %q", "olá", r)
+	}
+
+	expected := map[string]any{
+		"q":       "hello",
+		"source":  "en",
+		"target":  "zt",
+		"format":  "text",
+		"api_key": "secret",
+	}
+	if !reflect.DeepEqual(body, expected) {
+		t.Fatalf("Expected request %v, got %v", expected, body)
+	}
+
+	// Texts in the same language aren't sent to the provider
+	body = nil
+	r, err = lt.Translate(context.Background(), EN, EN, "hello", Options{})
+	if err != nil || r != "hello" {
+		t.Fatalf("Expected the same text, got %q and %v", r, err)
+	} else if body != nil {
+		t.Fatalf("Expected no request, got %v", body)
+	}
+}
+
+func TestLibreTranslateTranslateBatch(t *testing.T) {
+	var count int
+	url := testServer(t, "POST /translate", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Q      []string `json:"q"`
+			Target string   `json:"target"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("Failed to decode request: %s", err)
+		}
+		count++
+
+		ts := make([]string, len(body.Q))
+		for i, q := range body.Q {
+			ts[i] = body.Target + ":" + q
+		}
+		// Spanish responds with a missing translation
+		if body.Target == "es" {
+			ts = ts[1:]
+		}
+		respondJSON(t, w, http.StatusOK, map[string][]string{"translatedText": ts})
+	})
+
+	lt := NewLibreTranslate(url, "", time.Second)
+	texts := []string{"hello", "world"}
+
+	res, err := lt.TranslateBatch(context.Background(), EN, []Language{EN, PT}, texts, Options{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	expected := map[Language]BatchResult{
+		EN: {Texts: texts},
+		PT: {Texts: []string{"pt:hello", "pt:world"}},
+	}
+	if !reflect.DeepEqual(res, expected) {
+		t.Fatalf("Expected %v, got %v", expected, res)
+	} else if count != 1 {
+		t.Fatalf("Expected a single request for all texts, got %d", count)
+	}
+
+	_, err = lt.TranslateBatch(context.Background(), EN, []Language{ES}, texts, Options{})
+	if !errors.Is(err, ErrBadResponse) {
+		t.Fatalf("Expected error %q, got %v", ErrBadResponse, err)
+	}
+}
+
+func TestLibreTranslateDetectAll(t *testing.T) {
+	var apiKey string
+	url := testServer(t, "POST /detect", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			APIKey string `json:"api_key"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("Failed to decode request: %s", err)
+		}
+		apiKey = body.APIKey
+
+		respondJSON(t, w, http.StatusOK, []map[string]any{
+			{"language": "es", "confidence": 20.0},
+			{"language": "pt", "confidence": 90.0},
+		})
+	})
+
+	lt := NewLibreTranslate(url, "secret", time.Second)
+	d, err := lt.DetectAll(context.Background(), "olá mundo")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	// Confidences are scaled to 0-1 and sorted from the most confident
+	expected := Detection{{PT, 0.9}, {ES, 0.2}}
+	if !reflect.DeepEqual(d, expected) {
+		t.Fatalf("Expected %v, got %v", expected, d)
+	} else if apiKey != "secret" {
+		t.Fatalf("Expected API key %q, got %q", "secret", apiKey)
+	}
+
+	l, err := lt.Detect(context.Background(), "olá mundo")
+	if err != nil || l != PT {
+		t.Fatalf("Expected %s, got %s and %v", PT, l, err)
+	}
+}
+
+func TestLibreTranslateStatus(t *testing.T) {
+	tests := []struct {
+		status int
+		err    error
+	}{
+		{http.StatusBadRequest, ErrBadRequest},
+		{http.StatusUnauthorized, ErrUnauthorized},
+		{http.StatusForbidden, ErrUnauthorized},
+		{http.StatusTooManyRequests, ErrRateLimited},
+		{http.StatusInternalServerError, ErrUnavailable},
+		{http.StatusBadGateway, ErrUnavailable},
+	}
+
+	for _, test := range tests {
+		t.Run(http.StatusText(test.status), func(t *testing.T) {
+			url := testServer(t, "POST /translate", func(w http.ResponseWriter, r *http.Request) {
+				respondJSON(t, w, test.status, map[string]string{"error": "failed"})
+			})
+
+			lt := NewLibreTranslate(url, "", time.Second)
+			_, err := lt.Translate(context.Background(), EN, PT, "hello", Options{})
+			if !errors.Is(err, test.err) {
+				t.Fatalf("Expected error %q, got %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestLibreTranslateResponseLimit(t *testing.T) {
+	url := testServer(t, "POST /translate", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(strings.Repeat("a", maxResponseSize+1)))
+	})
+
+	lt := NewLibreTranslate(url, "", time.Second)
+	_, err := lt.Translate(context.Background(), EN, PT, "hello", Options{})
+	if !errors.Is(err, ErrBadResponse) {
+		t.Fatalf("Expected error %q, got %v", ErrBadResponse, err)
+	}
+}
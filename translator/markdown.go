@@ -0,0 +1,253 @@
+package translator
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Spans of Discord messages that should never be translated. Block spans split
+// the message into separately translated segments, while inline spans are
+// replaced by placeholders so the provider still sees the whole sentence.
+var markdownSpans = regexp.MustCompile(
+	"(" +
+		"```[\\s\\S]*?```" + // Code blocks
+		"|\\n+" +
+		"|(?m:^(?:>>> |> |#{1,3} |-# |[-*] |\\d+\\. ))" + // Quotes, headers and lists
+		")|(" +
+		"`[^`\\n]+`" + // Inline code
+		"|<a?:\\w+:\\d+>" + // Custom emojis
+		"|<(?:@[!&]?|#)\\d+>" + // User, role and channel mentions
+		"|</[\\w -]+:\\d+>" + // Slash command mentions
+		"|<t:-?\\d+(?::[tTdDfFR])?>" + // Timestamps
+		"|<https?://[^\\s>]+>" + // Links without embeds
+		"|\\]\\(<?https?://[^\\s)]+>?\\)" + // Masked links
+		"|https?://[^\\s<>()]+" + // Links
+		"|@everyone|@here" +
+		"|⟦g\\d+⟧" + // Glossary terms
+		"|\\*+|_+|~~|\\|\\|" + // Bold, italics, underline, strikethrough and spoilers
+		")",
+)
+
+var markdownPlaceholder = regexp.MustCompile(`⟦\s*(\d+)\s*⟧`)
+
+// Markdown decorates a Translator so Discord's markdown, code, mentions, emojis,
+// timestamps and links are preserved as is in the translated text.
+type Markdown struct {
+	translator Translator
+}
+
+func NewMarkdown(t Translator) Markdown {
+	return Markdown{t}
+}
+
+func (t Markdown) Translate(
+	ctx context.Context,
+	from, to Language,
+	text string,
+	opts Options,
+) (string, error) {
+	return t.translate(text, func(s string) (string, error) {
+		return t.translator.Translate(ctx, from, to, s, opts)
+	})
+}
+
+func (t Markdown) TranslateWithProvider(
+	ctx context.Context,
+	from, to Language,
+	text string,
+	opts Options,
+) (string, string, error) {
+	pt, ok := t.translator.(ProviderTranslator)
+	if !ok {
+		r, err := t.Translate(ctx, from, to, text, opts)
+		return r, "", err
+	}
+
+	var provider string
+	r, err := t.translate(text, func(s string) (string, error) {
+		r, p, err := pt.TranslateWithProvider(ctx, from, to, s, opts)
+		provider = p
+		return r, err
+	})
+
+	return r, provider, err
+}
+
+// Translates the segments of all texts in a single batch.
+func (t Markdown) TranslateBatch(
+	ctx context.Context,
+	from Language,
+	to []Language,
+	texts []string,
+	opts Options,
+) (map[Language]BatchResult, error) {
+	segs := make([][]markdownSegment, len(texts))
+	var sources []string
+	for i, text := range texts {
+		segs[i] = parseMarkdown(text)
+		sources = append(sources, markdownSources(segs[i])...)
+	}
+
+	br, err := TranslateBatch(ctx, t.translator, from, to, sources, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[Language]BatchResult, len(to))
+	for _, l := range to {
+		r := BatchResult{Texts: make([]string, len(texts)), Provider: br[l].Provider}
+		ts := br[l].Texts
+		for i, s := range segs {
+			n := len(markdownSources(s))
+			r.Texts[i] = renderMarkdown(s, ts[:n])
+			ts = ts[n:]
+		}
+		res[l] = r
+	}
+
+	return res, nil
+}
+
+func (t Markdown) Detect(ctx context.Context, text string) (Language, error) {
+	return t.translator.Detect(ctx, StripMarkdown(text))
+}
+
+func (t Markdown) DetectAll(ctx context.Context, text string) (Detection, error) {
+	return t.translator.DetectAll(ctx, StripMarkdown(text))
+}
+
+func (t Markdown) Languages(ctx context.Context) ([]Language, error) {
+	if lp, ok := t.translator.(LanguageProvider); ok {
+		return lp.Languages(ctx)
+	}
+	return LanguageCodes(), nil
+}
+
+func (t Markdown) translate(text string, f func(string) (string, error)) (string, error) {
+	segs := parseMarkdown(text)
+	sources := markdownSources(segs)
+
+	ts := make([]string, len(sources))
+	for i, s := range sources {
+		var err error
+		if ts[i], err = f(s); err != nil {
+			return "", err
+		}
+	}
+
+	return renderMarkdown(segs, ts), nil
+}
+
+// A part of a message, either kept verbatim or translated.
+type markdownSegment struct {
+	// Text with placeholders in place of inline spans, without the surrounding
+	// whitespace which providers usually don't preserve.
+	text        string
+	lead, trail string
+	tokens      []string
+	translate   bool
+}
+
+func parseMarkdown(text string) []markdownSegment {
+	var segs []markdownSegment
+	var seg strings.Builder
+	var tokens []string
+
+	flush := func() {
+		s := seg.String()
+		core := strings.TrimSpace(s)
+		lead := s[:strings.Index(s, core)]
+
+		segs = append(segs, markdownSegment{
+			text:      core,
+			lead:      lead,
+			trail:     s[len(lead)+len(core):],
+			tokens:    tokens,
+			translate: strings.ContainsFunc(core, unicode.IsLetter),
+		})
+
+		seg.Reset()
+		tokens = nil
+	}
+
+	i := 0
+	for _, m := range markdownSpans.FindAllStringSubmatchIndex(text, -1) {
+		seg.WriteString(text[i:m[0]])
+		i = m[1]
+
+		if m[2] != -1 {
+			flush()
+			segs = append(segs, markdownSegment{text: text[m[0]:m[1]]})
+		} else {
+			seg.WriteString(fmt.Sprintf("⟦%d⟧", len(tokens)))
+			tokens = append(tokens, text[m[0]:m[1]])
+		}
+	}
+	seg.WriteString(text[i:])
+	flush()
+
+	return segs
+}
+
+// Returns the texts of the segments which need to be translated.
+func markdownSources(segs []markdownSegment) []string {
+	var ss []string
+	for _, s := range segs {
+		if s.translate {
+			ss = append(ss, s.text)
+		}
+	}
+	return ss
+}
+
+// Joins the segments back, using the translations in the order returned by
+// markdownSources.
+func renderMarkdown(segs []markdownSegment, translations []string) string {
+	var r strings.Builder
+	for _, s := range segs {
+		text := s.text
+		if s.translate {
+			text, translations = translations[0], translations[1:]
+		}
+		r.WriteString(s.lead)
+		r.WriteString(restorePlaceholders(text, s.tokens))
+		r.WriteString(s.trail)
+	}
+	return r.String()
+}
+
+// Replaces placeholders with their original tokens. Tokens which the provider
+// dropped are appended to the end, so mentions and links are never lost, except
+// for formatting markers which would be meaningless out of place.
+func restorePlaceholders(s string, tokens []string) string {
+	used := make([]bool, len(tokens))
+
+	s = markdownPlaceholder.ReplaceAllStringFunc(s, func(p string) string {
+		i, err := strconv.Atoi(markdownPlaceholder.FindStringSubmatch(p)[1])
+		if err != nil || i >= len(tokens) {
+			return p
+		}
+		used[i] = true
+		return tokens[i]
+	})
+
+	for i, t := range tokens {
+		if !used[i] && strings.ContainsFunc(t, func(r rune) bool {
+			return unicode.IsLetter(r) || unicode.IsDigit(r)
+		}) {
+			s += " " + t
+		}
+	}
+
+	return s
+}
+
+// Removes all spans that shouldn't be translated, leaving only the
+// natural-language text of the message.
+func StripMarkdown(text string) string {
+	return strings.TrimSpace(markdownSpans.ReplaceAllString(text, " "))
+}
@@ -0,0 +1,169 @@
+package translator
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Translates texts to upper case, recording the texts it was asked to translate.
+type upperTranslator struct {
+	sources *[]string
+}
+
+func (t upperTranslator) Translate(
+	ctx context.Context,
+	from, to Language,
+	text string,
+	opts Options,
+) (string, error) {
+	*t.sources = append(*t.sources, text)
+	return strings.ToUpper(text), nil
+}
+
+func (t upperTranslator) Detect(ctx context.Context, text string) (Language, error) {
+	return EN, nil
+}
+
+func (t upperTranslator) DetectAll(ctx context.Context, text string) (Detection, error) {
+	return Detection{{EN, 1}}, nil
+}
+
+func TestMarkdownTranslate(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+		sources  []string
+	}{
+		{"Plain", "hello world", "HELLO WORLD", []string{"hello world"}},
+		{
+			"CodeBlock",
+			"look:\n```go\nfmt.Println(\"hi\")\n```\nbye",
+			"LOOK:\n```go\nfmt.Println(\"hi\")\n```\nBYE",
+			[]string{"look:", "bye"},
+		},
+		{"InlineCode", "run `go test` now", "RUN `go test` NOW", []string{"run ⟦0⟧ now"}},
+		{"Quote", "> quoted text", "> QUOTED TEXT", []string{"quoted text"}},
+		{"BlockQuote", ">>> quoted\ntext", ">>> QUOTED\nTEXT", []string{"quoted", "text"}},
+		{"List", "- one\n- two\n1. three", "- ONE\n- TWO\n1. THREE", []string{"one", "two", "three"}},
+		{"Header", "## title", "## TITLE", []string{"title"}},
+		{
+			"Mentions",
+			"hi <@123> and <@!124> in <#456> with <@&789>",
+			"HI <@123> AND <@!124> IN <#456> WITH <@&789>",
+			[]string{"hi ⟦0⟧ and ⟦1⟧ in ⟦2⟧ with ⟦3⟧"},
+		},
+		{"Everyone", "@everyone look", "@everyone LOOK", []string{"⟦0⟧ look"}},
+		{
+			"SlashCommand",
+			"use </channel link:123> here",
+			"USE </channel link:123> HERE",
+			[]string{"use ⟦0⟧ here"},
+		},
+		{
+			"CustomEmoji",
+			"nice <:pog:123456> <a:party:789>",
+			"NICE <:pog:123456> <a:party:789>",
+			[]string{"nice ⟦0⟧ ⟦1⟧"},
+		},
+		{
+			"Timestamps",
+			"meet <t:1700000000:R> or <t:1700000000>",
+			"MEET <t:1700000000:R> OR <t:1700000000>",
+			[]string{"meet ⟦0⟧ or ⟦1⟧"},
+		},
+		{
+			"MaskedLink",
+			"see [the docs](https://example.com/a_b) now",
+			"SEE [THE DOCS](https://example.com/a_b) NOW",
+			[]string{"see [the docs⟦0⟧ now"},
+		},
+		{
+			"Link",
+			"go to https://example.com/Path?q=a_b ok",
+			"GO TO https://example.com/Path?q=a_b OK",
+			[]string{"go to ⟦0⟧ ok"},
+		},
+		{
+			"LinkWithoutEmbed",
+			"go to <https://example.com/Path>",
+			"GO TO <https://example.com/Path>",
+			[]string{"go to ⟦0⟧"},
+		},
+		{"Spoiler", "a ||secret|| b", "A ||SECRET|| B", []string{"a ⟦0⟧secret⟦1⟧ b"}},
+		{"Formatting", "**bold** _it_", "**BOLD** _IT_", []string{"⟦0⟧bold⟦1⟧ ⟦2⟧it⟦3⟧"}},
+		{"NothingToTranslate", "<@123> <:pog:1> 42", "<@123> <:pog:1> 42", nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var sources []string
+			md := NewMarkdown(upperTranslator{&sources})
+
+			r, err := md.Translate(context.Background(), EN, PT, test.text, Options{})
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			} else if r != test.expected {
+				t.Fatalf("Expected %q, got %q", test.expected, r)
+			} else if !reflect.DeepEqual(sources, test.sources) {
+				t.Fatalf("Expected sources %q, got %q", test.sources, sources)
+			}
+		})
+	}
+}
+
+func TestMarkdownTranslateBatch(t *testing.T) {
+	var sources []string
+	md := NewMarkdown(upperTranslator{&sources})
+
+	texts := []string{"> hi <@1>\nbye", "<@2>", "`code` ok"}
+	res, err := md.TranslateBatch(context.Background(), EN, []Language{PT}, texts, Options{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	expected := []string{"> HI <@1>\nBYE", "<@2>", "`code` OK"}
+	if !reflect.DeepEqual(res[PT].Texts, expected) {
+		t.Fatalf("Expected %q, got %q", expected, res[PT].Texts)
+	}
+}
+
+func TestRestorePlaceholders(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		tokens   []string
+		expected string
+	}{
+		{"InOrder", "a ⟦0⟧ b ⟦1⟧", []string{"<@1>", "<@2>"}, "a <@1> b <@2>"},
+		{"Reordered", "⟦1⟧ b ⟦0⟧", []string{"<@1>", "<@2>"}, "<@2> b <@1>"},
+		{"Spaced", "a ⟦ 0 ⟧ b", []string{"<#3>"}, "a <#3> b"},
+		{"Repeated", "⟦0⟧ ⟦0⟧", []string{"<@1>"}, "<@1> <@1>"},
+		{
+			"DroppedMention",
+			"hello",
+			[]string{"<@1>", "https://example.com"},
+			"hello <@1> https://example.com",
+		},
+		{"DroppedFormatting", "hello", []string{"**", "**"}, "hello"},
+		{"PartiallyDropped", "⟦1⟧ hello", []string{"<@1>", "<@2>"}, "<@2> hello <@1>"},
+		{"OutOfRange", "hello ⟦5⟧", []string{"<@1>"}, "hello ⟦5⟧ <@1>"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if r := restorePlaceholders(test.text, test.tokens); r != test.expected {
+				t.Fatalf("Expected %q, got %q", test.expected, r)
+			}
+		})
+	}
+}
+
+func TestStripMarkdown(t *testing.T) {
+	r := StripMarkdown("> **hello** <@123> `code` https://example.com world")
+	if f := strings.Fields(r); !reflect.DeepEqual(f, []string{"hello", "world"}) {
+		t.Fatalf("Expected only the words hello and world, got %q", r)
+	}
+}
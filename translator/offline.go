@@ -0,0 +1,238 @@
+package translator
+
+import (
+	"context"
+	_ "embed"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+//go:embed data/phrases.csv
+var defaultPhrases string
+
+var offlineWords = regexp.MustCompile(`[\p{L}\p{N}]+(?:['’-][\p{L}\p{N}]+)*`)
+
+// PhraseTable holds the same phrases in many languages, used by Offline to
+// translate texts word by word.
+type PhraseTable struct {
+	languages []Language
+	rows      [][]string
+	// Lowercased phrases of each language and their row.
+	index    map[Language]map[string]int
+	maxWords int
+}
+
+// Parses a CSV file where the header has the language codes of each column, and
+// each row has the same phrase in all the languages. Empty cells are phrases
+// without a translation to the language.
+func ParsePhraseTable(r io.Reader) (PhraseTable, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+
+	rows, err := cr.ReadAll()
+	if err != nil {
+		return PhraseTable{}, errors.Join(errors.New("Failed to parse phrase table"), err)
+	} else if len(rows) == 0 {
+		return PhraseTable{}, errors.New("Phrase table has no header")
+	}
+
+	t := PhraseTable{
+		languages: make([]Language, len(rows[0])),
+		rows:      rows[1:],
+		index:     make(map[Language]map[string]int, len(rows[0])),
+	}
+	for i, c := range rows[0] {
+		l, err := ParseLanguage(c)
+		if err != nil {
+			return PhraseTable{}, errors.Join(fmt.Errorf("Invalid language in column %d", i+1), err)
+		}
+		t.languages[i] = l
+		t.index[l] = make(map[string]int)
+	}
+
+	for r, row := range t.rows {
+		for i, p := range row {
+			ws := offlineWords.FindAllString(strings.ToLower(p), -1)
+			if len(ws) == 0 {
+				continue
+			}
+
+			// The first row of a phrase has priority
+			k := strings.Join(ws, " ")
+			if _, ok := t.index[t.languages[i]][k]; !ok {
+				t.index[t.languages[i]][k] = r
+			}
+			t.maxWords = max(t.maxWords, len(ws))
+		}
+	}
+
+	return t, nil
+}
+
+// Returns the phrase table bundled with the package, with common phrases in
+// English, Portuguese, Spanish, French, German and Italian.
+func DefaultPhraseTable() PhraseTable {
+	t, err := ParsePhraseTable(strings.NewReader(defaultPhrases))
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func (t PhraseTable) Languages() []Language {
+	return t.languages
+}
+
+// Translates each phrase of the text found in the table, longest phrases first.
+// Words without a translation are kept as is.
+func (t PhraseTable) Translate(from, to Language, text string) string {
+	src, ok := t.index[from]
+	col := t.column(to)
+	if !ok || col == -1 || from == to {
+		return text
+	}
+
+	ms := offlineWords.FindAllStringIndex(text, -1)
+
+	var r strings.Builder
+	last := 0
+	for i := 0; i < len(ms); {
+		n, row := t.match(src, text, ms[i:])
+		if n == 0 || col >= len(t.rows[row]) || t.rows[row][col] == "" {
+			i++
+			continue
+		}
+
+		start, end := ms[i][0], ms[i+n-1][1]
+		r.WriteString(text[last:start])
+		r.WriteString(matchCase(text[start:end], t.rows[row][col]))
+		last = end
+		i += n
+	}
+	r.WriteString(text[last:])
+
+	return r.String()
+}
+
+// Returns how many words of the longest phrase starting at the first word, and
+// the phrase's row. Only words separated by whitespace form a phrase.
+func (t PhraseTable) match(index map[string]int, text string, ms [][]int) (int, int) {
+	for n := min(t.maxWords, len(ms)); n > 0; n-- {
+		ws := make([]string, n)
+		ok := true
+		for j := 0; j < n; j++ {
+			if j > 0 && strings.TrimSpace(text[ms[j-1][1]:ms[j][0]]) != "" {
+				ok = false
+				break
+			}
+			ws[j] = strings.ToLower(text[ms[j][0]:ms[j][1]])
+		}
+		if !ok {
+			continue
+		}
+
+		if row, ok := index[strings.Join(ws, " ")]; ok {
+			return n, row
+		}
+	}
+	return 0, 0
+}
+
+func (t PhraseTable) column(l Language) int {
+	for i, tl := range t.languages {
+		if tl == l {
+			return i
+		}
+	}
+	return -1
+}
+
+// Capitalizes the replacement if the original phrase was capitalized, or
+// uppercases it if the original was all uppercase.
+func matchCase(original, replacement string) string {
+	if strings.ToUpper(original) == original && strings.ToLower(original) != original &&
+		utf8.RuneCountInString(original) > 1 {
+		return strings.ToUpper(replacement)
+	}
+
+	o, _ := utf8.DecodeRuneInString(original)
+	r, n := utf8.DecodeRuneInString(replacement)
+	if unicode.IsUpper(o) {
+		return string(unicode.ToUpper(r)) + replacement[n:]
+	}
+	return replacement
+}
+
+// Offline is a deterministic Translator which doesn't need any service, using a
+// PhraseTable to translate and a TrigramDetector to detect languages. It is
+// meant for tests and air-gapped deployments.
+type Offline struct {
+	phrases  PhraseTable
+	detector TrigramDetector
+	annotate bool
+}
+
+// Creates an Offline translator. If annotate is true, translations are prefixed
+// with the language pair and Options used, for example "[pt→en] Hello" or
+// "[pt→en informal] Hello".
+func NewOffline(phrases PhraseTable, detector TrigramDetector, annotate bool) Offline {
+	return Offline{phrases, detector, annotate}
+}
+
+func (t Offline) Translate(
+	ctx context.Context,
+	from, to Language,
+	text string,
+	opts Options,
+) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if from == to || text == "" {
+		return text, nil
+	}
+
+	r := t.phrases.Translate(from, to, text)
+	if t.annotate {
+		if o := opts.String(); o != "" {
+			r = fmt.Sprintf("[%s→%s %s] %s", from, to, o, r)
+		} else {
+			r = fmt.Sprintf("[%s→%s] %s", from, to, r)
+		}
+	}
+
+	return r, nil
+}
+
+func (t Offline) Detect(ctx context.Context, text string) (Language, error) {
+	d, err := t.DetectAll(ctx, text)
+	if err != nil {
+		return "", err
+	}
+	return d[0].Language, nil
+}
+
+func (t Offline) DetectAll(ctx context.Context, text string) (Detection, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	d := t.detector.DetectAll(text)
+	if len(d) == 0 {
+		return nil, errors.Join(ErrBadRequest, errors.New("No language detected"))
+	}
+
+	return d, nil
+}
+
+// Returns the languages of the phrase table, other languages are still
+// accepted but their texts are kept as is.
+func (t Offline) Languages(ctx context.Context) ([]Language, error) {
+	return t.phrases.Languages(), nil
+}
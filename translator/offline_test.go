@@ -0,0 +1,112 @@
+package translator
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestPhraseTableTranslate(t *testing.T) {
+	pt := DefaultPhraseTable()
+
+	tests := []struct {
+		name     string
+		from, to Language
+		text     string
+		expected string
+	}{
+		{"Word", EN, PT, "hello", "olá"},
+		{"Capitalized", EN, PT, "Hello", "Olá"},
+		{"Uppercase", EN, PT, "HELLO", "OLÁ"},
+		{"LongestPhrase", EN, PT, "Good morning and thank you!", "Bom dia e obrigado!"},
+		{"Reverse", PT, EN, "olá, bom dia", "hello, good morning"},
+		{"UnknownWords", EN, ES, "hello stranger", "hola stranger"},
+		{"PhraseAcrossPunctuation", EN, PT, "good. morning", "bom. morning"},
+		{"SameLanguage", EN, EN, "hello", "hello"},
+		{"UnknownLanguage", EN, JA, "hello", "hello"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if r := pt.Translate(test.from, test.to, test.text); r != test.expected {
+				t.Fatalf("Expected %q, got %q", test.expected, r)
+			}
+		})
+	}
+}
+
+func TestParsePhraseTable(t *testing.T) {
+	pt, err := ParsePhraseTable(strings.NewReader("en,pt\nthe cat,o gato\ncat,\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	// Phrases without a translation are kept as is
+	if r := pt.Translate(EN, PT, "the cat, cat"); r != "o gato, cat" {
+		t.Fatalf("Expected %q, got %q", "o gato, cat", r)
+	}
+
+	if _, err := ParsePhraseTable(strings.NewReader("en,nope\n")); err == nil {
+		t.Fatal("Expected error for an invalid language column")
+	}
+}
+
+func TestTrigramDetector(t *testing.T) {
+	d := DefaultTrigramDetector()
+
+	tests := []struct {
+		text     string
+		expected Language
+	}{
+		{"Olá, tudo bem? Eu não sei onde está a minha casa, mas vou procurar amanhã.", PT},
+		{"Hello, how are you? I don't know where my house is, but I will look tomorrow.", EN},
+		{"Hola, ¿cómo estás? No sé dónde está mi casa, pero la buscaré mañana.", ES},
+		{"Bonjour, comment ça va ? Je ne sais pas où est ma maison, je chercherai demain.", FR},
+	}
+
+	for _, test := range tests {
+		t.Run(string(test.expected), func(t *testing.T) {
+			r := d.DetectAll(test.text)
+			if len(r) == 0 || r[0].Language != test.expected {
+				t.Fatalf("Expected %s first, got %v", test.expected, r)
+			}
+
+			sum := 0.0
+			for _, l := range r {
+				sum += l.Confidence
+			}
+			if sum < 0.999 || sum > 1.001 {
+				t.Fatalf("Expected confidences to add up to 1, got %f", sum)
+			}
+		})
+	}
+
+	if r := d.DetectAll("123 !!"); len(r) != 0 {
+		t.Fatalf("Expected no detection for text without letters, got %v", r)
+	}
+}
+
+func TestOfflineAnnotate(t *testing.T) {
+	o := NewOffline(DefaultPhraseTable(), DefaultTrigramDetector(), true)
+
+	r, err := o.Translate(context.Background(), PT, EN, "Olá", Options{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	} else if r != "[pt→en] Hello" {
+		t.Fatalf("Expected %q, got %q", "[pt→en] Hello", r)
+	}
+
+	opts := Options{Formality: FormalityInformal}
+	r, err = o.Translate(context.Background(), PT, EN, "Olá", opts)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	} else if r != "[pt→en informal] Hello" {
+		t.Fatalf("Expected %q, got %q", "[pt→en informal] Hello", r)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := o.Translate(ctx, PT, EN, "Olá", Options{}); err == nil {
+		t.Fatal("Expected error with a cancelled context")
+	}
+}
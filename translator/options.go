@@ -0,0 +1,56 @@
+package translator
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrUnknownFormality = errors.New("Unknown formality")
+
+type Formality string
+
+const (
+	FormalityDefault  Formality = ""
+	FormalityFormal   Formality = "formal"
+	FormalityInformal Formality = "informal"
+)
+
+func ParseFormality(s string) (Formality, error) {
+	switch f := Formality(strings.ToLower(strings.TrimSpace(s))); f {
+	case FormalityDefault, FormalityFormal, FormalityInformal:
+		return f, nil
+	case "default":
+		return FormalityDefault, nil
+	default:
+		return "", errors.Join(ErrUnknownFormality, fmt.Errorf("Formality %q is not supported", s))
+	}
+}
+
+func (f Formality) String() string {
+	if f == FormalityDefault {
+		return "default"
+	}
+	return string(f)
+}
+
+// Options of how texts are translated. Providers which don't support an option
+// ignore it.
+type Options struct {
+	Formality Formality
+	// Keep profanity as is, instead of letting the provider censor it.
+	PreserveProfanity bool
+}
+
+// Returns a short description of the options, empty if all options are the
+// defaults.
+func (o Options) String() string {
+	var s []string
+	if o.Formality != FormalityDefault {
+		s = append(s, o.Formality.String())
+	}
+	if o.PreserveProfanity {
+		s = append(s, "profanity")
+	}
+	return strings.Join(s, ",")
+}
@@ -0,0 +1,338 @@
+package translator
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	ErrQueueFull   = errors.New("Translation queue is full")
+	ErrQueueClosed = errors.New("Translation queue is closed")
+)
+
+type Priority int
+
+const (
+	PriorityLow Priority = iota
+	PriorityNormal
+	PriorityHigh
+)
+
+type QueueStats struct {
+	Depth     int
+	Processed uint64
+	Rejected  uint64
+	// Average time requests waited in the queue.
+	AverageWait time.Duration
+	MaxWait     time.Duration
+}
+
+// Queue limits how many requests are made to the Translator at the same time,
+// queueing the rest. Requests with higher priority are always made first, and
+// requests are rejected with ErrQueueFull if there are already size requests
+// of the same priority waiting.
+type Queue struct {
+	translator Translator
+	queues     [PriorityHigh + 1]chan *queueJob
+	stop       chan struct{}
+	closed     atomic.Bool
+	wg         sync.WaitGroup
+
+	processed atomic.Uint64
+	rejected  atomic.Uint64
+	waited    atomic.Int64
+	maxWait   atomic.Int64
+}
+
+type queueJob struct {
+	f       func()
+	done    chan struct{}
+	state   atomic.Int32
+	created time.Time
+}
+
+const (
+	jobQueued int32 = iota
+	jobStarted
+	jobCanceled
+)
+
+func NewQueue(t Translator, workers, size int) *Queue {
+	q := &Queue{translator: t, stop: make(chan struct{})}
+	for i := range q.queues {
+		q.queues[i] = make(chan *queueJob, size)
+	}
+
+	q.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go q.work()
+	}
+
+	return q
+}
+
+// Returns a Translator which queues all requests with the priority.
+func (q *Queue) WithPriority(p Priority) Translator {
+	return queued{q, p}
+}
+
+func (q *Queue) Translate(
+	ctx context.Context,
+	from, to Language,
+	text string,
+	opts Options,
+) (string, error) {
+	return queued{q, PriorityNormal}.Translate(ctx, from, to, text, opts)
+}
+
+func (q *Queue) TranslateWithProvider(
+	ctx context.Context,
+	from, to Language,
+	text string,
+	opts Options,
+) (string, string, error) {
+	return queued{q, PriorityNormal}.TranslateWithProvider(ctx, from, to, text, opts)
+}
+
+func (q *Queue) TranslateBatch(
+	ctx context.Context,
+	from Language,
+	to []Language,
+	texts []string,
+	opts Options,
+) (map[Language]BatchResult, error) {
+	return queued{q, PriorityNormal}.TranslateBatch(ctx, from, to, texts, opts)
+}
+
+func (q *Queue) Detect(ctx context.Context, text string) (Language, error) {
+	return queued{q, PriorityNormal}.Detect(ctx, text)
+}
+
+func (q *Queue) DetectAll(ctx context.Context, text string) (Detection, error) {
+	return queued{q, PriorityNormal}.DetectAll(ctx, text)
+}
+
+func (q *Queue) Languages(ctx context.Context) ([]Language, error) {
+	return queued{q, PriorityNormal}.Languages(ctx)
+}
+
+func (q *Queue) Stats() QueueStats {
+	depth := 0
+	for _, c := range q.queues {
+		depth += len(c)
+	}
+
+	p := q.processed.Load()
+	var avg time.Duration
+	if p > 0 {
+		avg = time.Duration(q.waited.Load() / int64(p))
+	}
+
+	return QueueStats{
+		Depth:       depth,
+		Processed:   p,
+		Rejected:    q.rejected.Load(),
+		AverageWait: avg,
+		MaxWait:     time.Duration(q.maxWait.Load()),
+	}
+}
+
+// Stops all workers, waiting for the requests being made to finish. Requests
+// still in the queue fail with ErrQueueClosed.
+func (q *Queue) Close() {
+	q.closed.Store(true)
+	close(q.stop)
+	q.wg.Wait()
+
+	for _, c := range q.queues {
+		for len(c) > 0 {
+			job := <-c
+			if job.state.CompareAndSwap(jobQueued, jobCanceled) {
+				close(job.done)
+			}
+		}
+	}
+}
+
+// Queues f and waits for it to be called. If the context is done before f is
+// called, it is removed from the queue and the context's error is returned.
+func (q *Queue) do(ctx context.Context, p Priority, f func()) error {
+	if q.closed.Load() {
+		return ErrQueueClosed
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	job := &queueJob{f: f, done: make(chan struct{}), created: time.Now()}
+
+	select {
+	case q.queues[p] <- job:
+	default:
+		q.rejected.Add(1)
+		return ErrQueueFull
+	}
+
+	select {
+	case <-job.done:
+	case <-ctx.Done():
+		if job.state.CompareAndSwap(jobQueued, jobCanceled) {
+			return ctx.Err()
+		}
+		// The job already started, f is also given the context so it should
+		// return shortly.
+		<-job.done
+	}
+
+	if job.state.Load() == jobCanceled {
+		return ErrQueueClosed
+	}
+	return nil
+}
+
+func (q *Queue) work() {
+	defer q.wg.Done()
+
+	for {
+		job, ok := q.next()
+		if !ok {
+			return
+		} else if !job.state.CompareAndSwap(jobQueued, jobStarted) {
+			continue
+		}
+
+		w := time.Since(job.created)
+		q.waited.Add(int64(w))
+		for m := q.maxWait.Load(); int64(w) > m && !q.maxWait.CompareAndSwap(m, int64(w)); {
+			m = q.maxWait.Load()
+		}
+
+		job.f()
+		close(job.done)
+		q.processed.Add(1)
+	}
+}
+
+// Returns the next job, always preferring the ones with higher priority.
+func (q *Queue) next() (*queueJob, bool) {
+	for p := PriorityHigh; p >= PriorityLow; p-- {
+		select {
+		case job := <-q.queues[p]:
+			return job, true
+		default:
+		}
+	}
+
+	select {
+	case job := <-q.queues[PriorityHigh]:
+		return job, true
+	case job := <-q.queues[PriorityNormal]:
+		return job, true
+	case job := <-q.queues[PriorityLow]:
+		return job, true
+	case <-q.stop:
+		return nil, false
+	}
+}
+
+type queued struct {
+	queue    *Queue
+	priority Priority
+}
+
+func (t queued) Translate(
+	ctx context.Context,
+	from, to Language,
+	text string,
+	opts Options,
+) (string, error) {
+	var r string
+	var err error
+	if qerr := t.queue.do(ctx, t.priority, func() {
+		r, err = t.queue.translator.Translate(ctx, from, to, text, opts)
+	}); qerr != nil {
+		return "", qerr
+	}
+	return r, err
+}
+
+func (t queued) TranslateWithProvider(
+	ctx context.Context,
+	from, to Language,
+	text string,
+	opts Options,
+) (string, string, error) {
+	pt, ok := t.queue.translator.(ProviderTranslator)
+	if !ok {
+		r, err := t.Translate(ctx, from, to, text, opts)
+		return r, "", err
+	}
+
+	var r, p string
+	var err error
+	if qerr := t.queue.do(ctx, t.priority, func() {
+		r, p, err = pt.TranslateWithProvider(ctx, from, to, text, opts)
+	}); qerr != nil {
+		return "", "", qerr
+	}
+	return r, p, err
+}
+
+func (t queued) TranslateBatch(
+	ctx context.Context,
+	from Language,
+	to []Language,
+	texts []string,
+	opts Options,
+) (map[Language]BatchResult, error) {
+	var r map[Language]BatchResult
+	var err error
+	if qerr := t.queue.do(ctx, t.priority, func() {
+		r, err = TranslateBatch(ctx, t.queue.translator, from, to, texts, opts)
+	}); qerr != nil {
+		return nil, qerr
+	}
+	return r, err
+}
+
+func (t queued) Detect(ctx context.Context, text string) (Language, error) {
+	var l Language
+	var err error
+	if qerr := t.queue.do(ctx, t.priority, func() {
+		l, err = t.queue.translator.Detect(ctx, text)
+	}); qerr != nil {
+		return "", qerr
+	}
+	return l, err
+}
+
+func (t queued) DetectAll(ctx context.Context, text string) (Detection, error) {
+	var d Detection
+	var err error
+	if qerr := t.queue.do(ctx, t.priority, func() {
+		d, err = t.queue.translator.DetectAll(ctx, text)
+	}); qerr != nil {
+		return nil, qerr
+	}
+	return d, err
+}
+
+func (t queued) Languages(ctx context.Context) ([]Language, error) {
+	lp, ok := t.queue.translator.(LanguageProvider)
+	if !ok {
+		return LanguageCodes(), nil
+	}
+	return lp.Languages(ctx)
+}
+
+// Returns a Translator which makes requests with the priority, if t supports
+// priorities, otherwise t is returned as is.
+func WithPriority(t Translator, p Priority) Translator {
+	if pt, ok := t.(interface{ WithPriority(Priority) Translator }); ok {
+		return pt.WithPriority(p)
+	}
+	return t
+}
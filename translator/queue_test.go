@@ -0,0 +1,175 @@
+package translator
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Records the texts it translates, blocking on "block" until released.
+type blockingTranslator struct {
+	started chan struct{}
+	release chan struct{}
+
+	mu    sync.Mutex
+	texts []string
+}
+
+func newBlockingTranslator() *blockingTranslator {
+	return &blockingTranslator{started: make(chan struct{}), release: make(chan struct{})}
+}
+
+func (t *blockingTranslator) Translate(
+	ctx context.Context,
+	from, to Language,
+	text string,
+	opts Options,
+) (string, error) {
+	if text == "block" {
+		t.started <- struct{}{}
+		<-t.release
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.texts = append(t.texts, text)
+	return text, nil
+}
+
+func (t *blockingTranslator) Detect(ctx context.Context, text string) (Language, error) {
+	return EN, nil
+}
+
+func (t *blockingTranslator) DetectAll(ctx context.Context, text string) (Detection, error) {
+	return Detection{{EN, 1}}, nil
+}
+
+// Starts a request which keeps the only worker of q busy until tr is released,
+// returning a channel closed when the request finishes.
+func blockQueue(t *testing.T, q *Queue, tr *blockingTranslator) chan struct{} {
+	t.Helper()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if _, err := q.Translate(context.Background(), EN, PT, "block", Options{}); err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+	}()
+	<-tr.started
+	return done
+}
+
+// Waits until depth requests are queued.
+func waitDepth(t *testing.T, q *Queue, depth int) {
+	t.Helper()
+	for i := 0; q.Stats().Depth != depth; i++ {
+		if i > 1000 {
+			t.Fatalf("Expected %d queued requests, got %d", depth, q.Stats().Depth)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestQueuePriority(t *testing.T) {
+	tr := newBlockingTranslator()
+	q := NewQueue(tr, 1, 10)
+	defer q.Close()
+
+	blocked := blockQueue(t, q, tr)
+
+	// Edits are queued before new messages, which are still translated first
+	var wg sync.WaitGroup
+	for i, p := range []Priority{PriorityLow, PriorityNormal, PriorityHigh} {
+		wg.Add(1)
+		go func(p Priority, text string) {
+			defer wg.Done()
+			_, err := WithPriority(q, p).Translate(context.Background(), EN, PT, text, Options{})
+			if err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			}
+		}(p, []string{"edit", "command", "message"}[i])
+		waitDepth(t, q, i+1)
+	}
+
+	close(tr.release)
+	<-blocked
+	wg.Wait()
+
+	expected := []string{"block", "message", "command", "edit"}
+	if !reflect.DeepEqual(tr.texts, expected) {
+		t.Fatalf("Expected translations in order %q, got %q", expected, tr.texts)
+	} else if s := q.Stats(); s.Processed != 4 || s.Depth != 0 {
+		t.Fatalf("Expected 4 processed requests, got %+v", s)
+	}
+}
+
+func TestQueueFull(t *testing.T) {
+	tr := newBlockingTranslator()
+	q := NewQueue(tr, 1, 1)
+	defer q.Close()
+
+	blocked := blockQueue(t, q, tr)
+
+	queued := make(chan error)
+	go func() {
+		_, err := q.Translate(context.Background(), EN, PT, "queued", Options{})
+		queued <- err
+	}()
+	waitDepth(t, q, 1)
+
+	_, err := q.Translate(context.Background(), EN, PT, "rejected", Options{})
+	if !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("Expected error %q, got %v", ErrQueueFull, err)
+	}
+
+	// Each priority has its own queue
+	high := make(chan error)
+	go func() {
+		_, err := WithPriority(q, PriorityHigh).Translate(context.Background(), EN, PT, "high", Options{})
+		high <- err
+	}()
+	waitDepth(t, q, 2)
+
+	close(tr.release)
+	<-blocked
+	if err := <-queued; err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	} else if err := <-high; err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	} else if s := q.Stats(); s.Rejected != 1 {
+		t.Fatalf("Expected 1 rejected request, got %+v", s)
+	}
+}
+
+func TestQueueCancel(t *testing.T) {
+	tr := newBlockingTranslator()
+	q := NewQueue(tr, 1, 10)
+
+	blocked := blockQueue(t, q, tr)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelled := make(chan error)
+	go func() {
+		_, err := q.Translate(ctx, EN, PT, "cancelled", Options{})
+		cancelled <- err
+	}()
+	waitDepth(t, q, 1)
+	cancel()
+	if err := <-cancelled; !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected error %q, got %v", context.Canceled, err)
+	}
+
+	close(tr.release)
+	<-blocked
+	q.Close()
+
+	if !reflect.DeepEqual(tr.texts, []string{"block"}) {
+		t.Fatalf("Expected the cancelled request to not be made, got %q", tr.texts)
+	}
+	_, err := q.Translate(context.Background(), EN, PT, "closed", Options{})
+	if !errors.Is(err, ErrQueueClosed) {
+		t.Fatalf("Expected error %q, got %v", ErrQueueClosed, err)
+	}
+}
@@ -0,0 +1,208 @@
+package translator
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// How many times a request is retried after the provider responds that too
+// many requests were made.
+const rateLimitRetries = 3
+
+const (
+	minRateLimitBackoff = time.Second
+	maxRateLimitBackoff = time.Minute
+)
+
+type RateLimitStats struct {
+	Requests  uint64
+	Throttled uint64
+	Waited    time.Duration
+}
+
+// RateLimit decorates a provider's Translator with a token bucket, so at most
+// burst requests are made at once and rate requests per second on average.
+// Requests which the provider rejects with ErrRateLimited are retried with an
+// exponential backoff, which also delays all other requests.
+type RateLimit struct {
+	translator Translator
+	rate       float64
+	burst      float64
+	// Bounds of the backoff after ErrRateLimited, minRateLimitBackoff and
+	// maxRateLimitBackoff outside of tests.
+	minBackoff time.Duration
+	maxBackoff time.Duration
+
+	mu           sync.Mutex
+	tokens       float64
+	last         time.Time
+	backoff      time.Duration
+	backoffUntil time.Time
+
+	requests  atomic.Uint64
+	throttled atomic.Uint64
+	waited    atomic.Int64
+}
+
+func NewRateLimit(t Translator, rate float64, burst int) *RateLimit {
+	return &RateLimit{
+		translator: t,
+		rate:       rate,
+		burst:      float64(burst),
+		tokens:     float64(burst),
+		last:       time.Now(),
+		minBackoff: minRateLimitBackoff,
+		maxBackoff: maxRateLimitBackoff,
+	}
+}
+
+func (r *RateLimit) Translate(
+	ctx context.Context,
+	from, to Language,
+	text string,
+	opts Options,
+) (string, error) {
+	var res string
+	err := r.do(ctx, 1, func() error {
+		var err error
+		res, err = r.translator.Translate(ctx, from, to, text, opts)
+		return err
+	})
+	return res, err
+}
+
+// Translations of a batch may need a request for each language, so a token
+// is taken for each one.
+func (r *RateLimit) TranslateBatch(
+	ctx context.Context,
+	from Language,
+	to []Language,
+	texts []string,
+	opts Options,
+) (map[Language]BatchResult, error) {
+	var res map[Language]BatchResult
+	err := r.do(ctx, len(to), func() error {
+		var err error
+		res, err = TranslateBatch(ctx, r.translator, from, to, texts, opts)
+		return err
+	})
+	return res, err
+}
+
+func (r *RateLimit) Detect(ctx context.Context, text string) (Language, error) {
+	var res Language
+	err := r.do(ctx, 1, func() error {
+		var err error
+		res, err = r.translator.Detect(ctx, text)
+		return err
+	})
+	return res, err
+}
+
+func (r *RateLimit) DetectAll(ctx context.Context, text string) (Detection, error) {
+	var res Detection
+	err := r.do(ctx, 1, func() error {
+		var err error
+		res, err = r.translator.DetectAll(ctx, text)
+		return err
+	})
+	return res, err
+}
+
+func (r *RateLimit) Languages(ctx context.Context) ([]Language, error) {
+	lp, ok := r.translator.(LanguageProvider)
+	if !ok {
+		return LanguageCodes(), nil
+	}
+
+	var res []Language
+	err := r.do(ctx, 1, func() error {
+		var err error
+		res, err = lp.Languages(ctx)
+		return err
+	})
+	return res, err
+}
+
+func (r *RateLimit) Stats() RateLimitStats {
+	return RateLimitStats{
+		Requests:  r.requests.Load(),
+		Throttled: r.throttled.Load(),
+		Waited:    time.Duration(r.waited.Load()),
+	}
+}
+
+func (r *RateLimit) do(ctx context.Context, tokens int, f func() error) error {
+	var err error
+	for i := 0; i <= rateLimitRetries; i++ {
+		if d := r.reserve(tokens); d > 0 {
+			r.waited.Add(int64(d))
+
+			t := time.NewTimer(d)
+			select {
+			case <-t.C:
+			case <-ctx.Done():
+				t.Stop()
+				r.unreserve(tokens)
+				return ctx.Err()
+			}
+		}
+
+		r.requests.Add(1)
+		err = f()
+		r.report(err)
+
+		if !errors.Is(err, ErrRateLimited) {
+			return err
+		}
+		r.throttled.Add(1)
+	}
+	return err
+}
+
+// Takes tokens from the bucket, returning how long to wait until they are
+// available. The bucket can go negative, so later requests wait in order.
+func (r *RateLimit) reserve(tokens int) time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	r.tokens = min(r.burst, r.tokens+now.Sub(r.last).Seconds()*r.rate)
+	r.last = now
+	r.tokens -= float64(tokens)
+
+	var d time.Duration
+	if r.tokens < 0 {
+		d = time.Duration(-r.tokens / r.rate * float64(time.Second))
+	}
+	if b := r.backoffUntil.Sub(now); b > d {
+		d = b
+	}
+
+	return d
+}
+
+// Returns tokens of a request which was cancelled while waiting for them, so
+// they can be used by other requests.
+func (r *RateLimit) unreserve(tokens int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tokens = min(r.burst, r.tokens+float64(tokens))
+}
+
+func (r *RateLimit) report(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !errors.Is(err, ErrRateLimited) {
+		r.backoff = 0
+		return
+	}
+
+	r.backoff = min(max(r.backoff*2, r.minBackoff), r.maxBackoff)
+	r.backoffUntil = time.Now().Add(r.backoff)
+}
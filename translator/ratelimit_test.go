@@ -0,0 +1,112 @@
+package translator
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// Responds with ErrRateLimited to the first failures requests.
+type rateLimitedTranslator struct {
+	failures int
+	calls    int
+}
+
+func (t *rateLimitedTranslator) Translate(
+	ctx context.Context,
+	from, to Language,
+	text string,
+	opts Options,
+) (string, error) {
+	t.calls++
+	if t.calls <= t.failures {
+		return "", ErrRateLimited
+	}
+	return text, nil
+}
+
+func (t *rateLimitedTranslator) Detect(ctx context.Context, text string) (Language, error) {
+	return EN, nil
+}
+
+func (t *rateLimitedTranslator) DetectAll(ctx context.Context, text string) (Detection, error) {
+	return Detection{{EN, 1}}, nil
+}
+
+func TestRateLimitBucket(t *testing.T) {
+	r := NewRateLimit(&rateLimitedTranslator{}, 20, 2)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := r.Translate(context.Background(), EN, PT, "hi", Options{}); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	}
+
+	// The burst is used right away, the third request waits for a new token
+	if d := time.Since(start); d < 40*time.Millisecond {
+		t.Fatalf("Expected the third request to wait for a token, took %s", d)
+	} else if s := r.Stats(); s.Requests != 3 || s.Waited <= 0 {
+		t.Fatalf("Expected 3 requests with a wait, got %+v", s)
+	}
+}
+
+func TestRateLimitBackoff(t *testing.T) {
+	tr := &rateLimitedTranslator{failures: 2}
+	r := NewRateLimit(tr, 1000, 10)
+	r.minBackoff = 20 * time.Millisecond
+	r.maxBackoff = 30 * time.Millisecond
+
+	start := time.Now()
+	if _, err := r.Translate(context.Background(), EN, PT, "hi", Options{}); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	// Backoffs of 20ms and then 30ms, doubled but capped at the maximum
+	if d := time.Since(start); d < 50*time.Millisecond {
+		t.Fatalf("Expected requests to back off after being rate limited, took %s", d)
+	} else if s := r.Stats(); s.Requests != 3 || s.Throttled != 2 {
+		t.Fatalf("Expected 3 requests with 2 throttled, got %+v", s)
+	}
+
+	// Successful requests reset the backoff
+	start = time.Now()
+	if _, err := r.Translate(context.Background(), EN, PT, "hi", Options{}); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	} else if d := time.Since(start); d >= r.minBackoff {
+		t.Fatalf("Expected no backoff after a success, took %s", d)
+	}
+
+	tr.calls, tr.failures = 0, rateLimitRetries+1
+	_, err := r.Translate(context.Background(), EN, PT, "hi", Options{})
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("Expected error %q, got %v", ErrRateLimited, err)
+	} else if tr.calls != rateLimitRetries+1 {
+		t.Fatalf("Expected %d requests, got %d", rateLimitRetries+1, tr.calls)
+	}
+}
+
+func TestRateLimitCancel(t *testing.T) {
+	tr := &rateLimitedTranslator{}
+	r := NewRateLimit(tr, 1, 1)
+
+	if _, err := r.Translate(context.Background(), EN, PT, "hi", Options{}); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := r.Translate(ctx, EN, PT, "hi", Options{})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected error %q, got %v", context.DeadlineExceeded, err)
+	} else if tr.calls != 1 {
+		t.Fatalf("Expected the cancelled request to not be made, got %d calls", tr.calls)
+	}
+
+	// The token of the cancelled request is returned, so the next one waits
+	// only for its own token instead of two
+	if d := r.reserve(1); d > 1100*time.Millisecond {
+		t.Fatalf("Expected to wait for a single token, got %s", d)
+	}
+}
@@ -0,0 +1,130 @@
+package translator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Google Translate uses some legacy or regional codes which differ from the
+// ones used by the rest of the package.
+var translateerCodes = map[Language]string{
+	ZH: "zh-CN",
+	HE: "iw",
+}
+
+type Translateer struct {
+	endpoint string
+	client   *http.Client
+}
+
+func NewTranslateer(endpoint string, timeout time.Duration) Translateer {
+	return Translateer{endpoint, &http.Client{Timeout: timeout}}
+}
+
+func (t Translateer) Translate(
+	ctx context.Context,
+	from, to Language,
+	text string,
+	opts Options,
+) (string, error) {
+	if from == to || text == "" {
+		return text, nil
+	}
+
+	res, err := t.get(
+		ctx,
+		providerCode(translateerCodes, from),
+		providerCode(translateerCodes, to),
+		text,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	return res.Result, nil
+}
+
+// Google Translate supports every language in the registry.
+func (t Translateer) Languages(ctx context.Context) ([]Language, error) {
+	return LanguageCodes(), nil
+}
+
+func (t Translateer) Detect(ctx context.Context, text string) (Language, error) {
+	d, err := t.DetectAll(ctx, text)
+	if err != nil {
+		return "", err
+	}
+	return d[0].Language, nil
+}
+
+// Google Translate only reports the detected language without any confidence
+// score, so it is always reported as certain.
+func (t Translateer) DetectAll(ctx context.Context, text string) (Detection, error) {
+	res, err := t.get(ctx, "auto", providerCode(translateerCodes, EN), text)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.From.ISO == "" {
+		return nil, errors.Join(ErrBadResponse, errors.New("No language detected"))
+	}
+
+	return Detection{{providerLanguage(translateerCodes, res.From.ISO), 1}}, nil
+}
+
+type translateerResponse struct {
+	Result string `json:"result"`
+	From   struct {
+		ISO string `json:"iso"`
+	} `json:"from"`
+}
+
+func (t Translateer) get(
+	ctx context.Context,
+	sl, tl, text string,
+) (translateerResponse, error) {
+	u, err := url.JoinPath(t.endpoint, "/api")
+	if err != nil {
+		return translateerResponse{}, errors.Join(ErrBadRequest, err)
+	}
+
+	q := url.Values{}
+	q.Set("text", text)
+	q.Set("sl", sl)
+	q.Set("tl", tl)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u+"?"+q.Encode(), nil)
+	if err != nil {
+		return translateerResponse{}, errors.Join(ErrBadRequest, err)
+	}
+
+	r, err := t.client.Do(req)
+	if err != nil {
+		return translateerResponse{}, errors.Join(ErrUnavailable, err)
+	}
+	defer r.Body.Close()
+
+	b, err := readResponse(r.Body)
+	if err != nil {
+		return translateerResponse{}, err
+	}
+
+	if r.StatusCode != http.StatusOK {
+		return translateerResponse{}, errors.Join(
+			statusErr(r.StatusCode),
+			fmt.Errorf("Translateer responded with status %d: %s", r.StatusCode, string(b)),
+		)
+	}
+
+	var res translateerResponse
+	if err := json.Unmarshal(b, &res); err != nil {
+		return translateerResponse{}, errors.Join(ErrBadResponse, err)
+	}
+
+	return res, nil
+}
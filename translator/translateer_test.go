@@ -0,0 +1,97 @@
+package translator
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTranslateerTranslate(t *testing.T) {
+	var query map[string]string
+	url := testServer(t, "GET /api", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		query = map[string]string{"text": q.Get("text"), "sl": q.Get("sl"), "tl": q.Get("tl")}
+
+		respondJSON(t, w, http.StatusOK, map[string]any{
+			"result": "你好",
+			"from":   map[string]string{"iso": "iw"},
+		})
+	})
+
+	tr := NewTranslateer(url, time.Second)
+	r, err := tr.Translate(context.Background(), HE, ZH, "שלום", Options{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	} else if r != "你好" {
+		t.Fatalf("Expected translation %q, got %q", "你好", r)
+	}
+
+	// Google Translate's legacy codes are used in requests
+	expected := map[string]string{"text": "שלום", "sl": "iw", "tl": "zh-CN"}
+	if !reflect.DeepEqual(query, expected) {
+		t.Fatalf("Expected query %v, got %v", expected, query)
+	}
+
+	d, err := tr.DetectAll(context.Background(), "שלום")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	} else if !reflect.DeepEqual(d, Detection{{HE, 1}}) {
+		t.Fatalf("Expected %v, got %v", Detection{{HE, 1}}, d)
+	} else if query["sl"] != "auto" {
+		t.Fatalf("Expected source language %q, got %q", "auto", query["sl"])
+	}
+}
+
+func TestTranslateerStatus(t *testing.T) {
+	tests := []struct {
+		status int
+		err    error
+	}{
+		{http.StatusBadRequest, ErrBadRequest},
+		{http.StatusForbidden, ErrUnauthorized},
+		{http.StatusTooManyRequests, ErrRateLimited},
+		{http.StatusServiceUnavailable, ErrUnavailable},
+	}
+
+	for _, test := range tests {
+		t.Run(http.StatusText(test.status), func(t *testing.T) {
+			url := testServer(t, "GET /api", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(test.status)
+			})
+
+			tr := NewTranslateer(url, time.Second)
+			_, err := tr.Translate(context.Background(), EN, PT, "hello", Options{})
+			if !errors.Is(err, test.err) {
+				t.Fatalf("Expected error %q, got %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestTranslateerBadResponse(t *testing.T) {
+	url := testServer(t, "GET /api", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("not json"))
+	})
+
+	tr := NewTranslateer(url, time.Second)
+	_, err := tr.Translate(context.Background(), EN, PT, "hello", Options{})
+	if !errors.Is(err, ErrBadResponse) {
+		t.Fatalf("Expected error %q, got %v", ErrBadResponse, err)
+	}
+}
+
+func TestTranslateerResponseLimit(t *testing.T) {
+	url := testServer(t, "GET /api", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(strings.Repeat("a", maxResponseSize+1)))
+	})
+
+	tr := NewTranslateer(url, time.Second)
+	_, err := tr.Translate(context.Background(), EN, PT, "hello", Options{})
+	if !errors.Is(err, ErrBadResponse) {
+		t.Fatalf("Expected error %q, got %v", ErrBadResponse, err)
+	}
+}
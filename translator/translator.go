@@ -1,10 +1,110 @@
 package translator
 
+import "context"
+
 type Translator interface {
-	// Translate a text from a language to another language
-	Translate(from, to Language, text string) (string, error)
+	// Translate a text from a language to another language, with the options
+	// the provider supports
+	Translate(ctx context.Context, from, to Language, text string, opts Options) (string, error)
 	// Detects the language of the text
+	Detect(ctx context.Context, text string) (Language, error)
+	// Detects the possible languages of the text, ranked by confidence
+	DetectAll(ctx context.Context, text string) (Detection, error)
+}
+
+// Translators written before contexts were added to the Translator interface.
+// Use FromLegacy to use them as a Translator.
+type LegacyTranslator interface {
+	Translate(from, to Language, text string) (string, error)
 	Detect(text string) (Language, error)
+	DetectAll(text string) (Detection, error)
+}
+
+// Adapts a LegacyTranslator to the Translator interface. Legacy translators
+// can't be cancelled, so each call runs in its own goroutine and the caller
+// stops waiting for it once the context is done.
+func FromLegacy(t LegacyTranslator) Translator {
+	return legacy{t}
+}
+
+type legacy struct {
+	translator LegacyTranslator
+}
+
+// Legacy translators don't support options, so they are ignored.
+func (t legacy) Translate(
+	ctx context.Context,
+	from, to Language,
+	text string,
+	opts Options,
+) (string, error) {
+	return wait(ctx, func() (string, error) {
+		return t.translator.Translate(from, to, text)
+	})
+}
+
+func (t legacy) Detect(ctx context.Context, text string) (Language, error) {
+	return wait(ctx, func() (Language, error) {
+		return t.translator.Detect(text)
+	})
+}
+
+func (t legacy) DetectAll(ctx context.Context, text string) (Detection, error) {
+	return wait(ctx, func() (Detection, error) {
+		return t.translator.DetectAll(text)
+	})
+}
+
+func (t legacy) Languages(ctx context.Context) ([]Language, error) {
+	lp, ok := t.translator.(interface{ Languages() ([]Language, error) })
+	if !ok {
+		return LanguageCodes(), nil
+	}
+	return wait(ctx, lp.Languages)
+}
+
+// Runs f in a new goroutine, returning early with the context's error if it is
+// done before f returns.
+func wait[T any](ctx context.Context, f func() (T, error)) (T, error) {
+	type result struct {
+		v   T
+		err error
+	}
+
+	if err := ctx.Err(); err != nil {
+		var zero T
+		return zero, err
+	}
+
+	c := make(chan result, 1)
+	go func() {
+		v, err := f()
+		c <- result{v, err}
+	}()
+
+	select {
+	case r := <-c:
+		return r.v, r.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+type DetectedLanguage struct {
+	Language Language
+	// Confidence of the detection, from 0 to 1
+	Confidence float64
+}
+
+// Detection is a list of candidate languages, the most confident first.
+type Detection []DetectedLanguage
+
+func (d Detection) Best() (DetectedLanguage, bool) {
+	if len(d) == 0 {
+		return DetectedLanguage{}, false
+	}
+	return d[0], true
 }
 
 type MockTranslator struct{}
@@ -13,10 +113,23 @@ func NewMockTranslator() MockTranslator {
 	return MockTranslator{}
 }
 
-func (t MockTranslator) Translate(from, to Language, text string) (string, error) {
+func (t MockTranslator) Translate(
+	ctx context.Context,
+	from, to Language,
+	text string,
+	opts Options,
+) (string, error) {
 	return text, nil
 }
 
-func (t MockTranslator) Detect(text string) (Language, error) {
+func (t MockTranslator) Detect(ctx context.Context, text string) (Language, error) {
 	return EN, nil
 }
+
+func (t MockTranslator) DetectAll(ctx context.Context, text string) (Detection, error) {
+	return Detection{{EN, 1}}, nil
+}
+
+func (t MockTranslator) Languages(ctx context.Context) ([]Language, error) {
+	return LanguageCodes(), nil
+}
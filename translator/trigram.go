@@ -0,0 +1,130 @@
+package translator
+
+import (
+	"cmp"
+	"embed"
+	"math"
+	"path"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+//go:embed data/samples
+var trigramSamples embed.FS
+
+// How much the softmax favors the most similar languages, higher values give
+// more confidence to the best match.
+const trigramSharpness = 30
+
+// TrigramDetector detects the language of texts by comparing the frequency of
+// their character trigrams with the ones of sample texts of each language.
+type TrigramDetector struct {
+	profiles map[Language]trigramProfile
+}
+
+type trigramProfile map[string]float64
+
+// Creates a detector from sample texts of each language, longer samples give
+// better results.
+func NewTrigramDetector(samples map[Language]string) TrigramDetector {
+	ps := make(map[Language]trigramProfile, len(samples))
+	for l, s := range samples {
+		ps[l] = newTrigramProfile(s)
+	}
+	return TrigramDetector{ps}
+}
+
+// Creates a detector from the samples bundled with the package.
+func DefaultTrigramDetector() TrigramDetector {
+	fs, err := trigramSamples.ReadDir("data/samples")
+	if err != nil {
+		panic(err)
+	}
+
+	samples := make(map[Language]string, len(fs))
+	for _, f := range fs {
+		b, err := trigramSamples.ReadFile(path.Join("data/samples", f.Name()))
+		if err != nil {
+			panic(err)
+		}
+		samples[Language(strings.TrimSuffix(f.Name(), path.Ext(f.Name())))] = string(b)
+	}
+
+	return NewTrigramDetector(samples)
+}
+
+// Returns all languages of the detector, ranked by how similar their samples
+// are to the text. Confidences add up to 1, and the detection is empty if
+// the text has no letters.
+func (d TrigramDetector) DetectAll(text string) Detection {
+	p := newTrigramProfile(text)
+	if len(p) == 0 {
+		return Detection{}
+	}
+
+	res := make(Detection, 0, len(d.profiles))
+	sum := 0.0
+	for l, lp := range d.profiles {
+		s := math.Exp(p.similarity(lp) * trigramSharpness)
+		res = append(res, DetectedLanguage{l, s})
+		sum += s
+	}
+	for i := range res {
+		res[i].Confidence /= sum
+	}
+
+	slices.SortFunc(res, func(a, b DetectedLanguage) int {
+		if c := cmp.Compare(b.Confidence, a.Confidence); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Language, b.Language)
+	})
+
+	return res
+}
+
+func (d TrigramDetector) Languages() []Language {
+	ls := make([]Language, 0, len(d.profiles))
+	for l := range d.profiles {
+		ls = append(ls, l)
+	}
+	slices.Sort(ls)
+	return ls
+}
+
+// Counts the trigrams of each word, padded with spaces so the beginning and end
+// of words are also taken in account. The profile is normalized to length 1.
+func newTrigramProfile(text string) trigramProfile {
+	p := make(trigramProfile)
+
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	for _, w := range words {
+		rs := []rune(" " + w + " ")
+		for i := 0; i+3 <= len(rs); i++ {
+			p[string(rs[i:i+3])]++
+		}
+	}
+
+	n := 0.0
+	for _, c := range p {
+		n += c * c
+	}
+	n = math.Sqrt(n)
+	for t, c := range p {
+		p[t] = c / n
+	}
+
+	return p
+}
+
+// Cosine similarity of two normalized profiles.
+func (p trigramProfile) similarity(o trigramProfile) float64 {
+	s := 0.0
+	for t, c := range p {
+		s += c * o[t]
+	}
+	return s
+}